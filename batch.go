@@ -0,0 +1,232 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package rosewood
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drgo/rosewood/table"
+)
+
+//DefaultOutputPattern names each output file after its input file and saves it in the same directory
+const DefaultOutputPattern = "{dir}/{name}.{ext}"
+
+//Status of a file processed by RunBatch
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped" //not processed because the batch was cancelled
+)
+
+//BatchOptions controls how RunBatch processes its input files
+type BatchOptions struct {
+	//Renderer is the name of a registered renderer; defaults to html
+	Renderer string
+	//OutputPattern is used to name output files. {dir} and {name} are replaced by the directory and the base
+	//name (without extension) of the input file, {ext} by the usual file extension of the renderer's output
+	//and {index} by the position of the input file (1-based). Defaults to DefaultOutputPattern
+	OutputPattern string
+	//Workers is the number of files processed at the same time; defaults to 1
+	Workers int
+}
+
+//Manifest describes the results of a batch run; it can be saved as json using WriteJSON
+type Manifest struct {
+	Renderer  string        `json:"renderer"`
+	Started   time.Time     `json:"started"`
+	Finished  time.Time     `json:"finished"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Files     []*FileResult `json:"files"` //in the same order as the input files
+}
+
+//FileResult describes the results of processing one input file
+type FileResult struct {
+	InputFileName string   `json:"input"`
+	Status        string   `json:"status"`
+	TableCount    int      `json:"tables"`
+	Warnings      []string `json:"warnings,omitempty"`
+	Errors        []string `json:"errors,omitempty"`
+	Outputs       []string `json:"outputs,omitempty"`
+}
+
+//WriteJSON writes the manifest as indented json
+func (m *Manifest) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(m); err != nil {
+		return fmt.Errorf("failed to write manifest: %s", err)
+	}
+	return nil
+}
+
+//RunBatch processes each file in job.RunOptions.InputFileNames and saves its output in a file named according to
+//opts.OutputPattern. Existing output files are only replaced if job.RunOptions.OverWriteOutputFile is true.
+//Errors in individual files do not stop the batch; they are reported in the returned manifest. An error is
+//returned only if the batch could not be started or if ctx was cancelled, in which case unprocessed files are
+//marked as skipped
+func RunBatch(ctx context.Context, job *Job, opts BatchOptions) (*Manifest, error) {
+	if opts.Renderer == "" {
+		opts.Renderer = "html"
+	}
+	if opts.OutputPattern == "" {
+		opts.OutputPattern = DefaultOutputPattern
+	}
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if _, err := GetRendererByName(opts.Renderer); err != nil { //fail early rather than once per file
+		return nil, err
+	}
+	inputs := job.RunOptions.InputFileNames
+	m := &Manifest{Renderer: opts.Renderer,
+		Started: time.Now(),
+		Files:   make([]*FileResult, len(inputs))}
+	outputs := make(map[string]string, len(inputs)) //output file name -> input file name
+	for i, in := range inputs {
+		out := outputFileName(opts.OutputPattern, in, i+1, outputExtension(opts.Renderer))
+		if prev, dup := outputs[out]; dup {
+			return nil, fmt.Errorf("output file name pattern %q gives %s and %s the same output file %s",
+				opts.OutputPattern, prev, in, out)
+		}
+		outputs[out] = in
+		m.Files[i] = &FileResult{InputFileName: in, Status: StatusSkipped, Outputs: []string{out}}
+	}
+	jobs := make(chan *FileResult)
+	var wg sync.WaitGroup
+	for n := 0; n < opts.Workers && n < len(inputs); n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for res := range jobs {
+				runBatchFile(ctx, job, opts.Renderer, res)
+			}
+		}()
+	}
+dispatch:
+	for _, res := range m.Files {
+		select {
+		case jobs <- res:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	for _, res := range m.Files {
+		switch res.Status {
+		case StatusOK:
+			m.Succeeded++
+		case StatusFailed:
+			m.Failed++
+		}
+		if res.Status != StatusOK { //nothing was saved
+			res.Outputs = nil
+		}
+	}
+	m.Finished = time.Now()
+	return m, ctx.Err()
+}
+
+//runBatchFile parses and renders one input file and records the outcome in res
+func runBatchFile(ctx context.Context, job *Job, rendererName string, res *FileResult) {
+	if ctx.Err() != nil {
+		return
+	}
+	fail := func(err error) {
+		res.Status = StatusFailed
+		if ctx.Err() != nil {
+			res.Status = StatusSkipped
+		}
+		for _, line := range strings.Split(err.Error(), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				res.Errors = append(res.Errors, line)
+			}
+		}
+	}
+	job.UI.Logf("***processing file %s\n", res.InputFileName)
+	in, err := os.Open(res.InputFileName)
+	if err != nil {
+		fail(err)
+		return
+	}
+	defer in.Close()
+	ri := NewInterpreter(job).SetScriptIdentifer(res.InputFileName)
+	file, err := ri.ParseContext(ctx, in, ri.ScriptIdentifer())
+	if file != nil {
+		res.TableCount = file.TableCount()
+		res.Warnings = file.Warnings()
+	}
+	if err != nil {
+		fail(ri.ReportError(err))
+		return
+	}
+	if ri.Settings().CheckSyntaxOnly {
+		res.Status, res.Outputs = StatusOK, nil
+		return
+	}
+	out := res.Outputs[0]
+	if !job.RunOptions.OverWriteOutputFile {
+		if _, err := os.Stat(out); err == nil {
+			fail(fmt.Errorf("output file %s already exists", out))
+			return
+		}
+	}
+	hr, err := GetRendererByName(rendererName) //each file gets its own renderer
+	if err != nil {
+		fail(err)
+		return
+	}
+	var buf bytes.Buffer //the output file is only created if rendering succeeds
+	err = ri.RenderContext(ctx, &buf, file, hr)
+	if w, ok := hr.(table.Warner); ok {
+		res.Warnings = append(res.Warnings, w.Warnings()...)
+	}
+	if err != nil {
+		fail(ri.ReportError(err))
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+		fail(err)
+		return
+	}
+	if err := ioutil.WriteFile(out, buf.Bytes(), 0644); err != nil {
+		fail(err)
+		return
+	}
+	res.Status = StatusOK
+}
+
+//outputFileName replaces the placeholders in pattern using the input file name
+func outputFileName(pattern, inputFileName string, index int, ext string) string {
+	base := filepath.Base(inputFileName)
+	r := strings.NewReplacer(
+		"{dir}", filepath.Dir(inputFileName),
+		"{name}", strings.TrimSuffix(base, filepath.Ext(base)),
+		"{ext}", ext,
+		"{index}", strconv.Itoa(index),
+	)
+	return filepath.Clean(r.Replace(pattern))
+}
+
+//outputExtension returns the file extension usually used for the output of the named renderer
+func outputExtension(rendererName string) string {
+	switch rendererName {
+	case "latex":
+		return "tex"
+	case "text":
+		return "txt"
+	}
+	return rendererName
+}
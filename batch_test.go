@@ -0,0 +1,117 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package rosewood
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/drgo/rosewood/table"
+)
+
+func init() {
+	RegisterRenderer(&RendererConfig{
+		Name: "batch-test",
+		Renderer: func() (table.Renderer, error) {
+			return &textRenderer{clones: new(int32)}, nil
+		},
+	})
+}
+
+func TestRunBatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rosewood-batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"one.rw":    cancelTab,
+		"two.rw":    cancelTab + "Table 2\n+++\nx|y|\n+++\n+++\n+++\n",
+		"bad.rw":    "+++\nTable 1\n+++\na|b|\n+++\n+++\nmerge row 1 col 1:5\n+++\n",
+		"exists.rw": cancelTab,
+	}
+	for name, src := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "out"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "out", "4-exists.txt"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	job := DefaultJob(DefaultSettings())
+	for _, name := range []string{"one.rw", "two.rw", "bad.rw", "exists.rw", "missing.rw"} {
+		job.RunOptions.InputFileNames = append(job.RunOptions.InputFileNames, filepath.Join(dir, name))
+	}
+	m, err := RunBatch(context.Background(), job, BatchOptions{
+		Renderer:      "batch-test",
+		OutputPattern: "{dir}/out/{index}-{name}.txt",
+		Workers:       3,
+	})
+	if err != nil {
+		t.Fatalf("RunBatch() error = %v", err)
+	}
+	type result struct {
+		status    string
+		tables    int
+		hasErrors bool
+		outputs   []string
+	}
+	want := []result{
+		{StatusOK, 1, false, []string{filepath.Join(dir, "out", "1-one.txt")}},
+		{StatusOK, 2, false, []string{filepath.Join(dir, "out", "2-two.txt")}},
+		{StatusFailed, 1, true, nil},
+		{StatusFailed, 1, true, nil},
+		{StatusFailed, 0, true, nil},
+	}
+	for i, res := range m.Files {
+		got := result{res.Status, res.TableCount, len(res.Errors) > 0, res.Outputs}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("RunBatch() file %s = %+v, want %+v (errors: %v)", res.InputFileName, got, want[i], res.Errors)
+		}
+	}
+	if got := m.Files[1].Warnings; !reflect.DeepEqual(got, []string{"2 tables"}) {
+		t.Errorf("RunBatch() warnings = %v, want the renderer's warnings", got)
+	}
+	if m.Succeeded != 2 || m.Failed != 3 {
+		t.Errorf("RunBatch() succeeded = %d, failed = %d, want 2 and 3", m.Succeeded, m.Failed)
+	}
+	if b, _ := ioutil.ReadFile(filepath.Join(dir, "out", "2-two.txt")); string(b) != "Table 1: a b c d e f g h i\nTable 2: x y\n" {
+		t.Errorf("RunBatch() output = %q", b)
+	}
+	if b, _ := ioutil.ReadFile(filepath.Join(dir, "out", "4-exists.txt")); string(b) != "old" {
+		t.Errorf("RunBatch() replaced an existing file without OverWriteOutputFile")
+	}
+	var buf bytes.Buffer
+	if err := m.WriteJSON(&buf); err != nil {
+		t.Fatalf("Manifest.WriteJSON() error = %v", err)
+	}
+	var decoded Manifest
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded.Files) != 5 {
+		t.Errorf("Manifest.WriteJSON() wrote invalid json: %v\n%s", err, buf.String())
+	}
+
+	_, err = RunBatch(context.Background(), job, BatchOptions{Renderer: "batch-test", OutputPattern: "{dir}/out.txt"})
+	if err == nil {
+		t.Errorf("RunBatch() with an output pattern that does not distinguish files: expected an error")
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	m, err = RunBatch(cancelled, job, BatchOptions{Renderer: "batch-test"})
+	if err != context.Canceled {
+		t.Errorf("RunBatch() error = %v, want %v", err, context.Canceled)
+	}
+	for _, res := range m.Files {
+		if res.Status != StatusSkipped {
+			t.Errorf("RunBatch() with a cancelled context: file %s status = %s, want %s", res.InputFileName, res.Status, StatusSkipped)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+//Command carpenter converts Rosewood files into html, docx and other formats.
+//
+//Usage:
+//
+//	carpenter run [flags] file...       render each file using the selected renderer
+//	carpenter check [flags] file...     check the syntax of each file without rendering it
+//	carpenter convert [flags] file      convert a file written using an older version of Rosewood
+//	carpenter init [flags] [file]       write a default configuration file (carpenter.mdson)
+//	carpenter list-renderers            list the available output formats
+//
+//Run carpenter <command> -h for the flags of each command. If no configuration file is specified,
+//carpenter.mdson in the current directory is used if found.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/drgo/core/ui"
+	"github.com/drgo/rosewood"
+	_ "github.com/drgo/rosewood/renderers/docx" //register renderers
+	"github.com/drgo/rosewood/renderers/html"
+	_ "github.com/drgo/rosewood/renderers/latex"
+	_ "github.com/drgo/rosewood/renderers/markdown"
+	_ "github.com/drgo/rosewood/renderers/text"
+	"github.com/drgo/rosewood/types"
+)
+
+//exit codes
+const (
+	exitOK      = 0
+	exitFailed  = 1 //one or more files could not be processed
+	exitBadArgs = 2
+)
+
+const usage = `carpenter converts Rosewood files into html, docx and other formats.
+
+Usage:
+	carpenter <command> [flags] [arguments]
+
+Commands:
+	run             render each file using the selected renderer
+	check           check the syntax of each file without rendering it
+	convert         convert a file written using an older version of Rosewood
+	init            write a default configuration file
+	list-renderers  list the available output formats
+	version         print the version of the Rosewood library
+
+Run carpenter <command> -h for the flags of each command.
+`
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	go func() { //stop processing files on ctrl-c
+		<-interrupted
+		signal.Stop(interrupted) //a second ctrl-c kills a run that does not stop
+		cancel()
+	}()
+	code := carpenter(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	cancel()
+	os.Exit(code)
+}
+
+//carpenter runs the command in args and returns the exit code
+func carpenter(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitBadArgs
+	}
+	cmd := &command{name: args[0], stdout: stdout, stderr: stderr}
+	switch cmd.name {
+	case "run":
+		return cmd.run(ctx, args[1:], false)
+	case "check":
+		return cmd.run(ctx, args[1:], true)
+	case "convert":
+		return cmd.convert(args[1:])
+	case "init":
+		return cmd.initConfig(args[1:])
+	case "list-renderers":
+		for _, name := range rosewood.GetRenderersList() {
+			fmt.Fprintln(stdout, name)
+		}
+		return exitOK
+	case "version":
+		fmt.Fprintln(stdout, "Rosewood library version", rosewood.LibVersion())
+		return exitOK
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	}
+	fmt.Fprintf(stderr, "carpenter: unknown command %q\n\n%s", cmd.name, usage)
+	return exitBadArgs
+}
+
+//command holds the state shared by all subcommands
+type command struct {
+	name           string
+	stdout, stderr io.Writer
+}
+
+//flagSet returns a FlagSet that reports errors to stderr instead of exiting
+func (c *command) flagSet(args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: carpenter %s [flags] %s\n", c.name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func (c *command) errorf(format string, a ...interface{}) int {
+	fmt.Fprintf(c.stderr, "carpenter %s: %s\n", c.name, fmt.Sprintf(format, a...))
+	return exitFailed
+}
+
+//loadJob returns the default job updated from configFileName. If configFileName is empty, carpenter.mdson
+//in the current directory is loaded if it exists. debug replaces the Debug option of the configuration
+//unless it is negative, ie the -debug flag was not set
+func (c *command) loadJob(configFileName string, debug int) (*rosewood.Job, error) {
+	job := rosewood.DefaultJob(rosewood.DefaultSettings())
+	if debug >= 0 { //also traces loading the configuration
+		job.SetUI(ui.NewUI(debug))
+		job.RunOptions.Debug = debug
+	}
+	if configFileName == "" {
+		if _, err := os.Stat(types.ConfigFileBaseName); err != nil {
+			return job, nil
+		}
+		configFileName = types.ConfigFileBaseName
+	}
+	if err := job.LoadFromMDSonFile(configFileName); err != nil {
+		return nil, err
+	}
+	job.RunOptions.ConfigFileName = configFileName
+	if debug >= 0 {
+		job.RunOptions.Debug = debug
+	}
+	job.SetDebugLevel(job.RunOptions.Debug)
+	return job, nil
+}
+
+//flagDebugLevel returns the level set by the -debug flag or -1 if the flag was not set explicitly, so that
+//its default does not replace the Debug option of the configuration
+func flagDebugLevel(fs *flag.FlagSet, debug int) int {
+	level := -1
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "debug" {
+			level = debug
+		}
+	})
+	return level
+}
+
+//run renders (or if checkOnly only parses) the input files and reports the outcome for each of them
+func (c *command) run(ctx context.Context, args []string, checkOnly bool) int {
+	fs := c.flagSet("file...")
+	configFileName := fs.String("config", "", "configuration `file` (default carpenter.mdson in the current directory if found)")
+	debug := fs.Int("debug", 0, "debug `level` (0-3)")
+	workers := fs.Int("workers", 1, "number of files processed at the same time")
+	var (
+		format, pattern, manifestFileName, styles, theme, layout *string
+		overwrite, indent, fragment, scoped                      *bool
+	)
+	if !checkOnly {
+		format = fs.String("format", "", "output `format`; see list-renderers (default html or OutputFormat in the configuration file)")
+		pattern = fs.String("o", "", "output file name `pattern` using {dir}, {name}, {ext} and {index} (default "+rosewood.DefaultOutputPattern+")")
+		overwrite = fs.Bool("overwrite", false, "replace existing output files")
+		manifestFileName = fs.String("manifest", "", "write a json report on the processed files to `file` (- for stdout)")
+		indent = fs.Bool("indent", false, "turn the leading spaces of first-column cells into indent levels")
+		styles = fs.String("styles", "", "load style definitions for docx, latex and text output from `file`")
+		theme = fs.String("theme", "", "html `theme`: "+strings.Join(html.ThemeNames(), ", ")+" or the name of a .css file in ThemePath")
+		fragment = fs.Bool("fragment", false, "write html tables without the document head and body, eg for embedding in other pages")
+		layout = fs.String("template", "", "html/template `file` that replaces the page, table, row and cell layouts of html output")
+		scoped = fs.Bool("scoped-css", false, "include the stylesheet in html fragments, limited to a wrapper div")
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitBadArgs
+	}
+	job, err := c.loadJob(*configFileName, flagDebugLevel(fs, *debug))
+	if err != nil {
+		return c.errorf("%s", err)
+	}
+	if fs.NArg() > 0 {
+		job.RunOptions.InputFileNames = fs.Args()
+	}
+	if len(job.RunOptions.InputFileNames) == 0 {
+		fmt.Fprintln(c.stderr, "carpenter "+c.name+": no input files")
+		fs.Usage()
+		return exitBadArgs
+	}
+	opts := rosewood.BatchOptions{Workers: *workers}
+	if checkOnly {
+		job.RosewoodSettings.CheckSyntaxOnly = true
+	} else {
+		opts.Renderer = firstNonEmpty(*format, job.RunOptions.OutputFormat)
+		opts.OutputPattern = outputPattern(*pattern, job)
+		if *overwrite {
+			job.RunOptions.OverWriteOutputFile = true
+		}
+		if *indent {
+			job.RosewoodSettings.IndentLevels = true
+		}
+		job.RosewoodSettings.StylesFileName = firstNonEmpty(*styles, job.RosewoodSettings.StylesFileName)
+		job.RosewoodSettings.Theme = firstNonEmpty(*theme, job.RosewoodSettings.Theme)
+		job.RosewoodSettings.HTMLTemplateName = firstNonEmpty(*layout, job.RosewoodSettings.HTMLTemplateName)
+		if *fragment {
+			job.RosewoodSettings.HTMLFragment = true
+		}
+		if *scoped {
+			job.RosewoodSettings.ScopedCSS = true
+		}
+	}
+	m, err := rosewood.RunBatch(ctx, job, opts)
+	if m == nil {
+		return c.errorf("%s", err)
+	}
+	c.report(m)
+	if !checkOnly && *manifestFileName != "" {
+		if merr := c.writeManifest(m, *manifestFileName); merr != nil {
+			return c.errorf("%s", merr)
+		}
+	}
+	if err != nil {
+		return c.errorf("%s", err)
+	}
+	if m.Failed > 0 {
+		return exitFailed
+	}
+	return exitOK
+}
+
+//report prints the outcome for each file followed by a summary
+func (c *command) report(m *rosewood.Manifest) {
+	for _, f := range m.Files {
+		for _, w := range f.Warnings {
+			fmt.Fprintf(c.stderr, "%s: warning: %s\n", f.InputFileName, w)
+		}
+		switch f.Status {
+		case rosewood.StatusOK:
+			if len(f.Outputs) > 0 {
+				fmt.Fprintf(c.stdout, "%s: %d table(s) saved to %s\n", f.InputFileName, f.TableCount, strings.Join(f.Outputs, ", "))
+			} else {
+				fmt.Fprintf(c.stdout, "%s: %d table(s), no errors found\n", f.InputFileName, f.TableCount)
+			}
+		default:
+			fmt.Fprintf(c.stderr, "%s: %s\n", f.InputFileName, f.Status)
+			for _, e := range f.Errors {
+				fmt.Fprintf(c.stderr, "\t%s\n", e)
+			}
+		}
+	}
+	fmt.Fprintf(c.stdout, "%d file(s) processed successfully, %d failed\n", m.Succeeded, m.Failed)
+}
+
+func (c *command) writeManifest(m *rosewood.Manifest, fileName string) error {
+	if fileName == "-" {
+		return m.WriteJSON(c.stdout)
+	}
+	return createFile(fileName, true, m.WriteJSON)
+}
+
+//convert converts a file from an older version of Rosewood to the current one
+func (c *command) convert(args []string) int {
+	fs := c.flagSet("file")
+	from := fs.String("from", "v0.1", "Rosewood `version` of the input file")
+	outFileName := fs.String("o", "", "output `file` (default stdout)")
+	overwrite := fs.Bool("overwrite", false, "replace the output file if it exists")
+	if err := fs.Parse(args); err != nil {
+		return exitBadArgs
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitBadArgs
+	}
+	in, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return c.errorf("%s", err)
+	}
+	defer in.Close()
+	settings := rosewood.DefaultSettings()
+	settings.ConvertFromVersion = *from
+	if *outFileName == "" {
+		if err := rosewood.ConvertToCurrentVersion(settings, in, c.stdout); err != nil {
+			return c.errorf("%s", err)
+		}
+		return exitOK
+	}
+	if err := createFile(*outFileName, *overwrite, func(w io.Writer) error {
+		return rosewood.ConvertToCurrentVersion(settings, in, w)
+	}); err != nil {
+		return c.errorf("%s", err)
+	}
+	fmt.Fprintf(c.stdout, "%s converted to %s\n", fs.Arg(0), *outFileName)
+	return exitOK
+}
+
+//initConfig writes a configuration file holding the default settings
+func (c *command) initConfig(args []string) int {
+	fs := c.flagSet("[file]")
+	overwrite := fs.Bool("overwrite", false, "replace the configuration file if it exists")
+	if err := fs.Parse(args); err != nil {
+		return exitBadArgs
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return exitBadArgs
+	}
+	fileName := types.ConfigFileBaseName
+	if fs.NArg() == 1 {
+		fileName = fs.Arg(0)
+	}
+	if _, err := os.Stat(fileName); err == nil && !*overwrite {
+		return c.errorf("file %s already exists; use -overwrite to replace it", fileName)
+	}
+	job := rosewood.DefaultJob(rosewood.DefaultSettings())
+	if err := job.SaveToMDSonFile(fileName, true); err != nil {
+		return c.errorf("%s", err)
+	}
+	fmt.Fprintf(c.stdout, "default configuration saved to %s\n", fileName)
+	return exitOK
+}
+
+//createFile creates fileName and passes it to write; the file is removed if write fails
+func createFile(fileName string, overwrite bool, write func(w io.Writer) error) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !overwrite {
+		flags |= os.O_EXCL
+	}
+	f, err := os.OpenFile(fileName, flags, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("file %s already exists; use -overwrite to replace it", fileName)
+		}
+		return err
+	}
+	if err = write(f); err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	if err != nil {
+		os.Remove(fileName)
+	}
+	return err
+}
+
+//outputPattern returns the -o flag if set. Otherwise, the OutputFileName of the job names the output of a
+//single input file; it is not used for several files, which would all be saved under the same name
+func outputPattern(flag string, job *rosewood.Job) string {
+	if flag == "" && len(job.RunOptions.InputFileNames) == 1 {
+		return job.RunOptions.OutputFileName
+	}
+	return flag
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, s := range values {
+		if s != "" {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,142 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/drgo/rosewood"
+)
+
+const validTab = `+++
+Table 1
++++
+a|b|
+c|d|
++++
++++
+style row 1 bold
++++
+`
+
+func TestCarpenter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "carpenter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	good := filepath.Join(dir, "good.rw")
+	bad := filepath.Join(dir, "bad.rw")
+	if err := ioutil.WriteFile(good, []byte(validTab), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(bad, []byte(strings.Replace(validTab, "style row 1 bold", "foo row 1", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	oldFile := filepath.Join("..", "..", "test-files", "bug-v1tov2-extra-style.txt")
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantOut    string //substring of stdout
+		wantErr    string //substring of stderr
+		wantOutput string //file that must exist after the command
+	}{
+		{"no command", nil, exitBadArgs, "", "Usage:", ""},
+		{"unknown command", []string{"build"}, exitBadArgs, "", `unknown command "build"`, ""},
+		{"list-renderers", []string{"list-renderers"}, exitOK, "docx\nhtml\nlatex\nmd\ntext\n", "", ""},
+		{"check ok", []string{"check", good}, exitOK, "1 table(s), no errors found", "", ""},
+		{"check failed", []string{"check", good, bad}, exitFailed, "1 file(s) processed successfully, 1 failed", "bad.rw: failed", ""},
+		{"run no files", []string{"run", "-config", ""}, exitBadArgs, "", "no input files", ""},
+		{"run", []string{"run", "-format", "md", good}, exitOK, "saved to " + filepath.Join(dir, "good.md"), "",
+			filepath.Join(dir, "good.md")},
+		{"run existing output", []string{"run", "-format", "md", good}, exitFailed, "", "already exists", ""},
+		{"run overwrite", []string{"run", "-format", "md", "-overwrite", good}, exitOK, "1 file(s) processed successfully", "", ""},
+		{"run pattern and manifest", []string{"run", "-format", "text", "-o", "{dir}/out/{name}.{ext}", "-manifest", "-", good}, exitOK,
+			`"status": "ok"`, "", filepath.Join(dir, "out", "good.txt")},
+		{"run missing styles file", []string{"run", "-format", "text", "-overwrite", "-styles", filepath.Join(dir, "none.txt"), good}, exitFailed,
+			"", "failed to load style definitions", ""},
+		{"run unknown theme", []string{"run", "-format", "html", "-overwrite", "-theme", "fancy", good}, exitFailed, "",
+			"unknown theme fancy", ""},
+		{"run missing template", []string{"run", "-format", "html", "-overwrite", "-template", filepath.Join(dir, "none.tmpl"), good},
+			exitFailed, "", "failed to load html template", ""},
+		{"run unknown format", []string{"run", "-format", "pdf", good}, exitFailed, "", "unknown renderer", ""},
+		{"convert", []string{"convert", oldFile}, exitOK, "+++", "", ""},
+		{"convert to file", []string{"convert", "-o", filepath.Join(dir, "new.rw"), oldFile}, exitOK, "converted", "",
+			filepath.Join(dir, "new.rw")},
+		{"convert existing file", []string{"convert", "-o", filepath.Join(dir, "new.rw"), oldFile}, exitFailed, "", "already exists", ""},
+		{"convert bad version", []string{"convert", "-from", "v9", oldFile}, exitFailed, "", "invalid version number", ""},
+		{"init", []string{"init", filepath.Join(dir, "carpenter.mdson")}, exitOK, "default configuration saved", "",
+			filepath.Join(dir, "carpenter.mdson")},
+		{"init existing file", []string{"init", filepath.Join(dir, "carpenter.mdson")}, exitFailed, "", "already exists", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := carpenter(context.Background(), tt.args, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("carpenter(%v) = %d, want %d\nstdout: %s\nstderr: %s", tt.args, code, tt.wantCode, stdout.String(), stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.wantOut) {
+				t.Errorf("carpenter(%v) stdout = %s, want it to contain %q", tt.args, stdout.String(), tt.wantOut)
+			}
+			if !strings.Contains(stderr.String(), tt.wantErr) {
+				t.Errorf("carpenter(%v) stderr = %s, want it to contain %q", tt.args, stderr.String(), tt.wantErr)
+			}
+			if tt.wantOutput != "" {
+				if _, err := os.Stat(tt.wantOutput); err != nil {
+					t.Errorf("carpenter(%v) did not create %s: %v", tt.args, tt.wantOutput, err)
+				}
+			}
+		})
+	}
+}
+
+func TestOutputPattern(t *testing.T) {
+	tests := []struct {
+		flag           string
+		inputs         []string
+		outputFileName string
+		want           string
+	}{
+		{"", []string{"a.rw"}, "out.html", "out.html"},
+		{"", []string{"a.rw", "b.rw"}, "out.html", ""},
+		{"{name}.htm", []string{"a.rw"}, "out.html", "{name}.htm"},
+		{"{name}.htm", []string{"a.rw", "b.rw"}, "", "{name}.htm"},
+	}
+	for _, tt := range tests {
+		job := rosewood.DefaultJob(rosewood.DefaultSettings())
+		job.RunOptions.InputFileNames, job.RunOptions.OutputFileName = tt.inputs, tt.outputFileName
+		if got := outputPattern(tt.flag, job); got != tt.want {
+			t.Errorf("outputPattern(%q) with inputs %v and OutputFileName %q = %q, want %q", tt.flag, tt.inputs,
+				tt.outputFileName, got, tt.want)
+		}
+	}
+}
+
+func TestFlagDebugLevel(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{nil, -1},
+		{[]string{"-debug", "0"}, 0},
+		{[]string{"-debug", "2", "a.rw"}, 2},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("run", flag.ContinueOnError)
+		debug := fs.Int("debug", 0, "")
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		if got := flagDebugLevel(fs, *debug); got != tt.want {
+			t.Errorf("flagDebugLevel(%v) = %d, want %d", tt.args, got, tt.want)
+		}
+	}
+}
@@ -4,8 +4,11 @@ package rosewood
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"sync"
 
 	"github.com/drgo/core/errors"
 	"github.com/drgo/core/ui"
@@ -33,8 +36,13 @@ func NewInterpreter(job *Job) *Interpreter {
 //Parse takes an io.Reader containing RoseWood script and an optional script identifier and returns
 // parsed tables and an error
 func (ri *Interpreter) Parse(r io.ReadSeeker, scriptIdentifer string) (*parser.File, error) {
+	return ri.ParseContext(context.Background(), r, scriptIdentifer)
+}
+
+//ParseContext is like Parse but stops and returns ctx.Err() if ctx is cancelled or times out
+func (ri *Interpreter) ParseContext(ctx context.Context, r io.ReadSeeker, scriptIdentifer string) (*parser.File, error) {
 	file := parser.NewFile(scriptIdentifer, ri.job)
-	if err := file.Parse(r); err != nil {
+	if err := file.ParseContext(ctx, r); err != nil {
 		return nil, err
 	}
 	if ri.job.RunOptions.Debug == ui.DebugAll {
@@ -48,6 +56,12 @@ func (ri *Interpreter) Parse(r io.ReadSeeker, scriptIdentifer string) (*parser.F
 
 //Render renders 1 or more tables into a Writer using the passed Renderer
 func (ri *Interpreter) Render(w io.Writer, file *parser.File, hr table.Renderer) error {
+	return ri.RenderContext(context.Background(), w, file, hr)
+}
+
+//RenderContext is like Render but stops and returns ctx.Err() if ctx is cancelled or times out.
+//Output written before cancellation is not flushed
+func (ri *Interpreter) RenderContext(ctx context.Context, w io.Writer, file *parser.File, hr table.Renderer) error {
 	var err error
 	bw := bufio.NewWriter(w) //buffer the writer to speed up writing
 	tables := file.Tables()
@@ -55,25 +69,117 @@ func (ri *Interpreter) Render(w io.Writer, file *parser.File, hr table.Renderer)
 	if err = hr.SetSettings(ri.settings); err != nil {
 		return fmt.Errorf("failed to render table: %s", err)
 	}
+	if ds, ok := hr.(table.DocumentSetter); ok { //renderer needs page settings
+		if err = ds.SetDocument(ri.job.Document); err != nil {
+			return fmt.Errorf("failed to render table: %s", err)
+		}
+	}
+	if js, ok := hr.(table.JobSetter); ok { //renderer uses job metadata
+		if err = js.SetJob(ri.job, file.FileName); err != nil {
+			return fmt.Errorf("failed to render table: %s", err)
+		}
+	}
 	_ = hr.SetTables(tables)
 	if err = hr.StartFile(); err != nil {
 		return fmt.Errorf("failed to render table: %s", err)
 	}
-	for i, t := range tables {
-		if err = t.Run(); err != nil {
-			return fmt.Errorf("failed to run one or more commands for table: %s", err)
-		}
-		ri.job.UI.Logf("****processed contents of table %d\n%v\n", i+1, t.ProcessedTableContents().DebugString())
-		if err = t.Render(w, hr); err != nil {
-			return fmt.Errorf("failed to render table %d: %s", i+1, err)
+	workers := ri.settings.MaxConcurrentWorkers
+	if workers > len(tables) {
+		workers = len(tables)
+	}
+	if cr, ok := hr.(table.Cloner); ok && workers > 1 {
+		err = ri.renderConcurrently(ctx, bw, tables, cr, workers)
+	} else {
+		for i, t := range tables {
+			if err = ri.renderTable(ctx, w, i, t, hr); err != nil {
+				break
+			}
 		}
 	}
+	if err != nil {
+		return err
+	}
 	if err = hr.EndFile(); err != nil {
 		return fmt.Errorf("failed to render table: %s", err)
 	}
 	return bw.Flush() //flush to ensure all changes are written to the writer
 }
 
+//renderTable runs the commands of table t, which is the ith table in the file, and renders it using hr
+func (ri *Interpreter) renderTable(ctx context.Context, w io.Writer, i int, t *table.Table, hr table.Renderer) error {
+	if err := t.RunContext(ctx); err != nil {
+		if ctx.Err() != nil { //return cancellation errors as is so that callers can check for them
+			return ctx.Err()
+		}
+		return fmt.Errorf("failed to run one or more commands for table: %s", err)
+	}
+	ri.job.UI.Logf("****processed contents of table %d\n%v\n", i+1, t.ProcessedTableContents().DebugString())
+	if err := t.RenderContext(ctx, w, hr); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("failed to render table %d: %s", i+1, err)
+	}
+	return nil
+}
+
+//renderConcurrently runs and renders tables using a pool of workers. Each worker renders into a separate
+//buffer per table using its own clone of hr; the buffers are written to w in source order
+func (ri *Interpreter) renderConcurrently(ctx context.Context, w io.Writer, tables []*table.Table, hr table.Cloner, workers int) error {
+	workCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		buf bytes.Buffer
+		err error
+	}
+	results := make([]result, len(tables))
+	clones := make([]table.Renderer, workers)
+	for n := range clones {
+		var err error
+		if clones[n], err = hr.Clone(); err != nil {
+			return fmt.Errorf("failed to render table: %s", err)
+		}
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for _, r := range clones {
+		wg.Add(1)
+		go func(r table.Renderer) {
+			defer wg.Done()
+			for i := range jobs {
+				r.SetWriter(&results[i].buf)
+				if results[i].err = ri.renderTable(workCtx, &results[i].buf, i, tables[i], r); results[i].err != nil {
+					cancel() //stop other workers
+				}
+			}
+		}(r)
+	}
+dispatch:
+	for i := range tables {
+		select {
+		case jobs <- i:
+		case <-workCtx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if ctx.Err() != nil { //cancelled by the caller
+		return ctx.Err()
+	}
+	for i := range results { //report the error of the first failing table
+		if err := results[i].err; err != nil && err != context.Canceled {
+			return err
+		}
+	}
+	for i := range results {
+		if _, err := results[i].buf.WriteTo(w); err != nil {
+			return fmt.Errorf("failed to render table %d: %s", i+1, err)
+		}
+	}
+	return nil
+}
+
 //ReportError returns a list of errors encountered during running
 func (ri *Interpreter) ReportError(err error) error {
 	return errors.ErrorsToError(err)
@@ -2,6 +2,164 @@
 
 package rosewood
 
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/drgo/rosewood/table"
+	"github.com/drgo/rosewood/types"
+)
+
+const cancelTab = `+++
+Table 1
++++
+a|b|c|
+d|e|f|
+g|h|i|
++++
++++
+style row 1:max col 1:max bold
++++
+`
+
+//cancellingRenderer cancels its context after writing a number of cells
+type cancellingRenderer struct {
+	cancel    context.CancelFunc
+	cellsLeft int
+	cells     int //number of written cells
+}
+
+func (cr *cancellingRenderer) SetWriter(w io.Writer) error                 { return nil }
+func (cr *cancellingRenderer) SetSettings(s *types.RosewoodSettings) error { return nil }
+func (cr *cancellingRenderer) SetTables(tables []*table.Table) error       { return nil }
+func (cr *cancellingRenderer) Err() error                                  { return nil }
+func (cr *cancellingRenderer) StartFile() error                            { return nil }
+func (cr *cancellingRenderer) EndFile() error                              { return nil }
+func (cr *cancellingRenderer) StartTable(t *table.Table) error             { return nil }
+func (cr *cancellingRenderer) EndTable(t *table.Table) error               { return nil }
+func (cr *cancellingRenderer) StartRow(r *table.Row) error                 { return nil }
+func (cr *cancellingRenderer) EndRow(r *table.Row) error                   { return nil }
+func (cr *cancellingRenderer) OutputCell(c *table.Cell) error {
+	cr.cells++
+	if cr.cellsLeft--; cr.cellsLeft == 0 {
+		cr.cancel()
+	}
+	return nil
+}
+
+func TestInterpreter_Context(t *testing.T) {
+	ri := NewInterpreter(DefaultJob(DefaultSettings()))
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ri.ParseContext(cancelled, strings.NewReader(cancelTab), "cancelled"); err != context.Canceled {
+		t.Errorf("ParseContext() error = %v, want %v", err, context.Canceled)
+	}
+	file, err := ri.ParseContext(context.Background(), strings.NewReader(cancelTab), "cancel")
+	if err != nil {
+		t.Fatalf("ParseContext() error = %v", err)
+	}
+	if err := file.Tables()[0].RunContext(cancelled); err != context.Canceled {
+		t.Errorf("Table.RunContext() error = %v, want %v", err, context.Canceled)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cr := &cancellingRenderer{cancel: cancel, cellsLeft: 4}
+	if err := ri.RenderContext(ctx, ioutil.Discard, file, cr); err != context.Canceled {
+		t.Errorf("RenderContext() error = %v, want %v", err, context.Canceled)
+	}
+	if cr.cells != 6 { //rendering stops at the end of the row in which the context was cancelled
+		t.Errorf("RenderContext() wrote %d cells after cancellation, want 6", cr.cells)
+	}
+}
+
+//textRenderer writes cell texts; it implements table.Cloner so it can be used concurrently
+type textRenderer struct {
+	w      io.Writer
+	tables []*table.Table
+	clones *int32 //number of created clones
+}
+
+func (tr *textRenderer) SetWriter(w io.Writer) error                 { tr.w = w; return nil }
+func (tr *textRenderer) SetSettings(s *types.RosewoodSettings) error { return nil }
+func (tr *textRenderer) SetTables(tables []*table.Table) error       { tr.tables = tables; return nil }
+func (tr *textRenderer) Err() error                                  { return nil }
+func (tr *textRenderer) StartFile() error                            { return nil }
+func (tr *textRenderer) EndFile() error                              { return nil }
+
+//Warnings implements table.Warner; files with several tables get a warning
+func (tr *textRenderer) Warnings() []string {
+	if len(tr.tables) > 1 {
+		return []string{fmt.Sprintf("%d tables", len(tr.tables))}
+	}
+	return nil
+}
+
+func (tr *textRenderer) StartTable(t *table.Table) error {
+	_, err := fmt.Fprintf(tr.w, "%s:", t.Caption.Lines[0])
+	return err
+}
+func (tr *textRenderer) EndTable(t *table.Table) error {
+	_, err := io.WriteString(tr.w, "\n")
+	return err
+}
+func (tr *textRenderer) StartRow(r *table.Row) error { return nil }
+func (tr *textRenderer) EndRow(r *table.Row) error   { return nil }
+func (tr *textRenderer) OutputCell(c *table.Cell) error {
+	_, err := io.WriteString(tr.w, " "+c.Text())
+	return err
+}
+func (tr *textRenderer) Clone() (table.Renderer, error) {
+	atomic.AddInt32(tr.clones, 1)
+	c := *tr
+	return &c, nil
+}
+
+func TestInterpreter_RenderConcurrently(t *testing.T) {
+	var src, want strings.Builder
+	for i := 1; i <= 50; i++ {
+		fmt.Fprintf(&src, "+++\nTable %d\n+++\n%d|a|\nb|c|\n+++\n+++\nstyle row 1 bold\n", i, i)
+		fmt.Fprintf(&want, "Table %d: %d a b c\n", i, i)
+	}
+	src.WriteString("+++\n")
+	tests := []struct {
+		name       string
+		workers    int
+		wantClones int32
+	}{
+		{"sequential", 1, 0},
+		{"concurrent", 4, 4},
+		{"more workers than tables", 100, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := DefaultSettings()
+			settings.MaxConcurrentWorkers = tt.workers
+			ri := NewInterpreter(DefaultJob(settings))
+			file, err := ri.Parse(strings.NewReader(src.String()), tt.name)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			var clones int32
+			w := &bytes.Buffer{}
+			if err := ri.Render(w, file, &textRenderer{clones: &clones}); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if w.String() != want.String() {
+				t.Errorf("Render() = %s, want %s", w.String(), want.String())
+			}
+			if clones != tt.wantClones {
+				t.Errorf("Render() created %d clones, want %d", clones, tt.wantClones)
+			}
+		})
+	}
+}
+
 // func TestInterpreter_Run(t *testing.T) {
 // 	const pathPrefix = "test-files/"
 // 	tests := []struct {
@@ -15,6 +15,13 @@ import (
 	"github.com/drgo/rosewood/types"
 )
 
+//tableSettings lists set commands that apply to the current table only. They are run while parsing,
+//in order, so that eg set tablefilename can replace the body section
+var tableSettings = map[string]bool{
+	"columnstarts":  true,
+	"tablefilename": true,
+}
+
 // CommandParser specialized parser for format commands
 type CommandParser struct {
 	// trace        trace.Tracer
@@ -24,7 +31,10 @@ type CommandParser struct {
 	// settings     *types.RosewoodSettings
 	position     Position
 	currentToken rune
-	tables       []*table.TableContents //list of all loaded tables
+	tables       []*table.TableContents //list of tables loaded by set tablefilename commands
+	columnStarts []int                  //column positions used to import fixed-width table files
+	baseDir      string                 //directory used to resolve relative file names in use commands
+	includes     []string               //chain of command files being loaded; used to detect circular use commands
 }
 
 //NewCommandParser initializes and returns a CommandParser
@@ -50,6 +60,15 @@ func (p *CommandParser) ErrorText(index int) string {
 	return p.errors.Get(index).Error() //TODO: will panic if index is out of bound
 }
 
+//LoadedTable returns the table contents loaded by the last set tablefilename command
+//in the most recently parsed command lines or nil if none was loaded
+func (p *CommandParser) LoadedTable() *table.TableContents {
+	if len(p.tables) == 0 {
+		return nil
+	}
+	return p.tables[len(p.tables)-1]
+}
+
 //Pos returns the current position in the source
 func (p *CommandParser) Pos() Position {
 	p.position.Column = p.lexer.Pos().Column
@@ -58,6 +77,7 @@ func (p *CommandParser) Pos() Position {
 
 //ParseCommandLines parses a list of strings into list of commands
 func (p *CommandParser) ParseCommandLines(s *types.Section) ([]*types.Command, error) {
+	p.tables, p.columnStarts = nil, nil
 	if len(s.Lines) == 0 {
 		return nil, nil
 	}
@@ -83,9 +103,12 @@ func (p *CommandParser) ParseCommandLines(s *types.Section) ([]*types.Command, e
 		errOffset := p.errors.Len()
 		cmdName, cmdToken := p.acceptCommandName()
 		cmd := types.NewCommand(cmdName, cmdToken)
+		cmd.SetLine(i + s.Offset)
 		switch cmdName {
 		case "set":
 			err = p.parseSetCommand(cmd)
+		case "use":
+			err = p.parseUseCommand(cmd)
 		default: //all other commands will be parsed as a formatting command
 			err = p.parseTableFormatCommand(cmd)
 		}
@@ -99,6 +122,25 @@ func (p *CommandParser) ParseCommandLines(s *types.Section) ([]*types.Command, e
 		}
 		if err = cmd.Finalize(); err != nil {
 			p.addSyntaxError(err.Error())
+			continue
+		}
+		if cmd.ID() == types.KwUse { //replace the use command with the commands loaded from its file
+			useList, err := p.runUseCommand(cmd)
+			if err != nil {
+				p.addSyntaxError("%s", err)
+				continue
+			}
+			for _, c := range useList { //point to the use command rather than a line in another file
+				c.SetLine(cmd.Line())
+			}
+			cmdList = append(cmdList, useList...)
+			continue
+		}
+		if cmd.ID() == types.KwSet && tableSettings[cmd.Args()[0]] {
+			if err = p.runSetCommand(cmd); err != nil {
+				p.addSyntaxError("%s", err)
+				continue
+			}
 		}
 		cmdList = append(cmdList, cmd)
 	}
@@ -135,12 +177,27 @@ func (p *CommandParser) parseSetCommand(cmd *types.Command) error {
 	p.nextToken()
 	settingName := p.acceptArg(scanner.Ident)
 	p.nextToken()
-	settingValue := p.acceptArg(scanner.String)
+	p.accept(scanner.String, "*setting value")
+	settingValue := p.lexer.TokenText() //not lower-cased b/c values like file names can be case-sensitive
 	p.nextToken()
 	cmd.AddArg(settingName, settingValue)
 	return nil
 }
 
+//parseUseCommand parses a command like `use "filename"`
+func (p *CommandParser) parseUseCommand(cmd *types.Command) error {
+	p.nextToken()
+	if p.currentToken != scanner.String {
+		return fmt.Errorf("expected a quoted file name, found %s", p.exactCurrentWord())
+	}
+	cmd.AddArg(p.lexer.TokenText()) //not lower-cased b/c file names can be case-sensitive
+	p.nextToken()
+	if p.currentToken != scanner.EOF {
+		return fmt.Errorf("expected end of line after file name, found %s", p.exactCurrentWord())
+	}
+	return nil
+}
+
 //acceptCommandName reads and validates a command name
 func (p *CommandParser) acceptCommandName() (string, types.RwKeyWord) {
 	p.nextToken()
@@ -4,8 +4,10 @@ package parser
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
+	"path/filepath"
 	"strings"
 	"text/scanner"
 
@@ -37,18 +39,26 @@ type File struct {
 	job      *types.Job
 	settings *types.RosewoodSettings
 	tables   []*table.Table //holds parsed tables and commands
+	warnings []string       //problems that do not stop processing
 }
 
 //NewFile returns a Rosewood File
 func NewFile(fileName string, job *types.Job) *File {
-	return &File{FileName: fileName,
+	f := &File{FileName: fileName,
 		job:      job,
 		parser:   NewCommandParser(job),
 		settings: job.RosewoodSettings}
+	f.parser.baseDir = filepath.Dir(fileName) //use commands are resolved relative to this file
+	return f
 }
 
 //Parse parses an io.ReadSeeker streaming a Rosewood file and returns any found tables
 func (f *File) Parse(r io.ReadSeeker) error {
+	return f.ParseContext(context.Background(), r)
+}
+
+//ParseContext is like Parse but stops and returns ctx.Err() if ctx is cancelled or times out
+func (f *File) ParseContext(ctx context.Context, r io.ReadSeeker) error {
 	//TODO: add a test file that starts with empty space or other stuff
 	var (
 		s       *types.Section
@@ -76,6 +86,9 @@ func (f *File) Parse(r io.ReadSeeker) error {
 	}
 	//process the rest of the file
 	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		lineNum++
 		line := scanner.Text()
 		if f.isSectionSeparatorLine(line) { //start of a new section
@@ -91,25 +104,37 @@ func (f *File) Parse(r io.ReadSeeker) error {
 	if err := scanner.Err(); err != nil {
 		return NewError(ErrSyntaxError, unknownPos, err.Error())
 	}
-	return f.createTables()
+	return f.createTables(ctx)
 }
 
 func (f *File) isSectionSeparatorLine(line string) bool {
 	return strings.HasPrefix(strings.TrimSpace(line), f.settings.SectionSeparator)
 }
 
+//columnSeparator returns the configured column separator or the default one if none configured
+func (f *File) columnSeparator() string {
+	if f.settings.ColumnSeparator == "" {
+		return table.DefaultColumnSeparator
+	}
+	return f.settings.ColumnSeparator
+}
+
 //SectionCount returns the number of sections found in the file
 func (f *File) SectionCount() int {
 	return len(f.sections)
 }
 
-func (f *File) createTables() error {
+func (f *File) createTables(ctx context.Context) error {
 	if f.SectionCount() == 0 || f.SectionCount()%f.settings.SectionsPerTable != 0 {
 		return fmt.Errorf("incorrect number of sections: %d", f.SectionCount())
 	}
 	var t *table.Table
 	var err error
+	bodySection := 0 //number of the body section of the current table
 	for i, s := range f.sections {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		ii := i + 1 //i is zero-based, section numbers should be one-based
 		s.Kind = types.SectionDescriptor(i%f.settings.SectionsPerTable + 1)
 		f.job.UI.Log("**** processing " + s.DebugString())
@@ -117,9 +142,14 @@ func (f *File) createTables() error {
 		switch s.Kind {
 		case types.SectionCaption:
 			t = table.NewTable(f.job.UI)
+			t.IndentLevels = f.settings.IndentLevels
 			t.Caption = s
 		case types.SectionBody:
-			if t.Contents, err = table.NewTableContents(s.String()); err != nil {
+			bodySection = ii
+			if strings.TrimSpace(s.String()) == "" { //body may be loaded by a set tablefilename command
+				continue
+			}
+			if t.Contents, err = table.NewTableContentsWithSeparator(s.String(), f.columnSeparator()); err != nil {
 				return NewError(ErrSyntaxError, unknownPos, fmt.Sprintf("error parsing table in section #%d: %s ", ii, err))
 			}
 		case types.SectionFootNotes:
@@ -128,6 +158,15 @@ func (f *File) createTables() error {
 			if t.CmdList, err = f.parser.ParseCommandLines(s); err != nil {
 				return err
 			}
+			if loaded := f.parser.LoadedTable(); loaded != nil { //replaces the body section
+				if t.Contents != nil {
+					f.warnf("section #%d: table body ignored because the table is loaded from a file", bodySection)
+				}
+				t.Contents = loaded
+			}
+			if t.Contents == nil {
+				return NewError(ErrSyntaxError, unknownPos, fmt.Sprintf("error parsing table in section #%d: empty table ", bodySection))
+			}
 			f.tables = append(f.tables, t)
 		default:
 			panic(fmt.Sprintf("invalid switch case [%v] in File.CreateTables()", s.Kind))
@@ -136,6 +175,15 @@ func (f *File) createTables() error {
 	return nil
 }
 
+//Warnings returns problems found while parsing that did not stop it, eg ignored table contents
+func (f *File) Warnings() []string {
+	return f.warnings
+}
+
+func (f *File) warnf(format string, a ...interface{}) {
+	f.warnings = append(f.warnings, fmt.Sprintf(format, a...))
+}
+
 //TableCount returns the number of prased tables in the file
 func (f *File) TableCount() int {
 	return len(f.tables)
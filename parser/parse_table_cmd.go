@@ -5,56 +5,113 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"text/scanner"
 
 	"github.com/drgo/rosewood/types"
 )
 
-//parseTableFormatCommand: parses a command like command row col args
+//parseTableFormatCommand: parses a command like command row col args. Each row or col segment may be followed
+//by except and a segment of the same kind, and union starts another block of row and col segments,
+//eg style row 2:max except row 5,9 col 2:max union row 1 col 1 shaded
 func (p *CommandParser) parseTableFormatCommand(cmd *types.Command) error {
-	//helper closure
-	parseSegment := func(modifier string) error {
-		ss, err := p.parseRowOrColSegment(cmd, modifier)
-		if err == nil {
-			cmd.AddSpanSegment(&ss)
-		}
-		return err
-	}
 	//next, we must parse either a row or col modifier
 	p.nextToken()
 	modifier := p.currentWord()
 	if modifier != "row" && modifier != "col" {
 		return fmt.Errorf("expected row or col, found %s", p.exactCurrentWord())
 	}
-	if err := parseSegment(modifier); err != nil {
+	block, err := p.parseSpanBlock(cmd)
+	if err != nil {
 		return err
 	}
-	//next, we either have another col/row modifier, argument or EOF (in case of merge command)
-	switch p.currentToken {
-	case scanner.EOF:
-	default:
+	for _, ss := range block {
+		cmd.AddSpanSegment(ss)
+	}
+	for p.currentToken == scanner.Ident && p.currentWord() == "union" {
+		p.nextToken()
+		if modifier = p.currentWord(); modifier != "row" && modifier != "col" {
+			return fmt.Errorf("expected row or col after union, found %s", p.exactCurrentWord())
+		}
+		if block, err = p.parseSpanBlock(cmd); err != nil {
+			return err
+		}
+		cmd.AddUnion(block)
+	}
+	//next, we have a where clause, arguments or EOF (in case of merge command)
+	if p.currentToken == scanner.Ident && p.currentWord() == "where" {
+		if err := p.parseCondition(cmd); err != nil {
+			return err
+		}
+	}
+	//format and align commands also take option values, eg format col 2 decimals 2 thousands "," or align col 2 on "("
+	takesValues := cmd.ID() == types.KwFormat || cmd.ID() == types.KwAlign
+	for ; p.currentToken == scanner.Ident || takesValues && isValueToken(p.currentToken); p.nextToken() {
+		if p.currentToken == scanner.String || p.currentToken == scanner.RawString { //quoted values are case-sensitive
+			cmd.AddArg(p.lexer.TokenText())
+			continue
+		}
+		arg := p.acceptArg(p.currentToken)
+		cmd.AddArg(arg)
+	}
+	if p.currentToken != scanner.EOF {
 		return fmt.Errorf("expected row, col or an argument, found %s", p.exactCurrentWord())
-	case scanner.Ident:
-		switch p.currentWord() {
-		case "col", "row":
-			if cmd.SpanSegment(p.currentWord()) != nil { //already parsed
-				return fmt.Errorf("duplicate %s", p.exactCurrentWord())
-			}
-			if err := parseSegment(p.currentWord()); err != nil {
-				return err
-			}
-			fallthrough
-		default: //read args
-			for ; p.currentToken == scanner.Ident; p.nextToken() {
-				arg := p.acceptArg(scanner.Ident)
-				cmd.AddArg(arg)
+	}
+	//success
+	return nil
+}
+
+func isValueToken(token rune) bool {
+	switch token {
+	case scanner.Int, scanner.Float, scanner.String, scanner.RawString:
+		return true
+	}
+	return false
+}
+
+//parseSpanBlock reads a row segment, a col segment or both (in any order), each optionally followed by except.
+//It stops at the first word that is not row or col
+func (p *CommandParser) parseSpanBlock(cmd *types.Command) ([]*types.SpanSegment, error) {
+	var block []*types.SpanSegment
+	for p.currentToken == scanner.Ident {
+		modifier := p.currentWord()
+		if modifier != "row" && modifier != "col" {
+			break
+		}
+		for _, ss := range block {
+			if ss.Kind() == modifier { //already parsed
+				return nil, fmt.Errorf("duplicate %s", p.exactCurrentWord())
 			}
-			if p.currentToken != scanner.EOF {
-				return fmt.Errorf("expected row, col or an argument, found %s", p.exactCurrentWord())
+		}
+		ss, err := p.parseRowOrColSegment(cmd, modifier)
+		if err != nil {
+			return nil, err
+		}
+		if p.currentToken == scanner.Ident && p.currentWord() == "except" {
+			if err := p.parseExcept(cmd, &ss); err != nil {
+				return nil, err
 			}
 		}
+		block = append(block, &ss)
 	}
-	//success
+	return block, nil
+}
+
+//parseExcept reads the rows or cols excluded from segment ss, eg except row 5,9. The parser is on except
+func (p *CommandParser) parseExcept(cmd *types.Command, ss *types.SpanSegment) error {
+	p.nextToken()
+	if modifier := p.currentWord(); modifier != ss.Kind() {
+		return fmt.Errorf("except %s must follow a %s segment, eg %s 2:max except %s 5", p.exactCurrentWord(),
+			modifier, modifier, modifier)
+	}
+	except, err := p.parseRowOrColSegment(cmd, ss.Kind())
+	if err != nil {
+		return err
+	}
+	if p.currentToken == scanner.Ident && p.currentWord() == "except" {
+		return fmt.Errorf("duplicate except in %s", ss)
+	}
+	ss.Except = &except
 	return nil
 }
 
@@ -63,11 +120,14 @@ func (p *CommandParser) parseRowOrColSegment(cmd *types.Command, modifier string
 	var err error
 	ss := types.NewSpanSegment(modifier)
 
-	if ss.Left, err = p.parsePoint(false /*unsigned int wanted*/); err != nil {
+	if err = p.nextNotNull(); err != nil {
 		return ss, err
 	}
-	if ss.Left == types.RwMax {
-		return ss, fmt.Errorf("max is not allowed in this position")
+	if p.currentToken == scanner.String || p.currentToken == scanner.RawString { //eg col "Total"
+		return ss, p.parseLabel(&ss)
+	}
+	if ss.Left, err = p.currentPoint(false /*unsigned int wanted*/); err != nil {
+		return ss, err
 	}
 	p.nextToken()
 	switch p.currentToken {
@@ -76,6 +136,9 @@ func (p *CommandParser) parseRowOrColSegment(cmd *types.Command, modifier string
 			return ss, err
 		}
 	case ',':
+		if types.IsFromEnd(ss.Left) {
+			return ss, fmt.Errorf("max is not allowed in this position")
+		}
 		ss.List = append(ss.List, ss.Left) //list has just begun
 		ss.Left = types.RwMissing
 		if err := p.parseCommaSepPoints(&ss); err != nil {
@@ -89,11 +152,90 @@ func (p *CommandParser) parseRowOrColSegment(cmd *types.Command, modifier string
 	return ss, nil
 }
 
+//parseCondition reads a where clause in the form where text|row n|col n operator value,
+//eg where col 4 < 0.05. Operators made of two chars (eg <=) are scanned as two tokens
+func (p *CommandParser) parseCondition(cmd *types.Command) error {
+	//regular expressions often contain escapes such as \. that are invalid in Go strings; accept them
+	p.lexer.Error = func(s *scanner.Scanner, msg string) {
+		if msg != "invalid char escape" {
+			p.scannerErrorHandler(s, msg)
+		}
+	}
+	defer func() { p.lexer.Error = p.scannerErrorHandler }()
+	if err := p.nextNotNull(); err != nil {
+		return err
+	}
+	subject, index := p.currentWord(), types.RwMissing
+	if subject == "row" || subject == "col" {
+		var err error
+		if index, err = p.parsePoint(false /*unsigned int wanted*/); err != nil {
+			return err
+		}
+	}
+	//read the operator
+	var op string
+	for p.nextToken(); strings.ContainsRune("~!=<>", p.currentToken); p.nextToken() {
+		op += string(p.currentToken)
+	}
+	if op == "" {
+		return fmt.Errorf("expected an operator in where clause, found %s", p.exactCurrentWord())
+	}
+	//read the value: a number, a quoted string or a word
+	var value string
+	switch p.currentToken {
+	case scanner.String, scanner.RawString: //used as is except for escaped quotes, eg "^0\.0"
+		text := p.lexer.TokenText()
+		value = strings.Replace(text[1:len(text)-1], `\"`, `"`, -1)
+	case scanner.Int, scanner.Float:
+		value = p.lexer.TokenText()
+	case scanner.Ident:
+		value = p.lexer.TokenText()
+		if p.lexer.Peek() == '.' { //'-' is an ident rune so -0.5 is scanned as -0 and .5
+			p.nextToken()
+			value += p.lexer.TokenText()
+		}
+	case '-', '+': //signed number
+		sign := string(p.currentToken)
+		p.nextToken()
+		if p.currentToken != scanner.Int && p.currentToken != scanner.Float {
+			return fmt.Errorf("expected a number in where clause, found %s", p.exactCurrentWord())
+		}
+		value = sign + p.lexer.TokenText()
+	default:
+		return fmt.Errorf("expected a value in where clause, found %s", p.exactCurrentWord())
+	}
+	condition, err := types.NewCondition(subject, index, op, value)
+	if err != nil {
+		return err
+	}
+	cmd.SetCondition(condition)
+	p.nextToken()
+	return nil
+}
+
+//parseLabel reads the quoted text that identifies a row or col. The label is resolved to a single row or col
+//when the command is run, so it cannot be part of a range or list
+func (p *CommandParser) parseLabel(ss *types.SpanSegment) error {
+	label, err := strconv.Unquote(p.lexer.TokenText())
+	if err != nil || strings.TrimSpace(label) == "" {
+		return fmt.Errorf("invalid %s label %s", ss.Kind(), p.exactCurrentWord())
+	}
+	ss.Label = strings.TrimSpace(label)
+	p.nextToken()
+	switch p.currentToken {
+	case scanner.Ident, scanner.EOF: //either "col"/"row" or an argument list or EOF
+		return nil
+	case p.job.RosewoodSettings.RangeOperator, ',':
+		return fmt.Errorf("a %s label cannot be part of a range or list: %s", ss.Kind(), p.exactCurrentWord())
+	}
+	return fmt.Errorf("unexpected token: %s", p.exactCurrentWord())
+}
+
 //parseRangePoints read a range of coordinate either left:right or left:skip step:right
 func (p *CommandParser) parseRangePoints(ss *types.SpanSegment) error {
 	var err error
 	parseRightCoord := func() error {
-		if ss.Right == types.RwMax { //comma not allowed after a max
+		if types.IsFromEnd(ss.Right) { //comma not allowed after a max
 			return fmt.Errorf("max is not allowed in this position")
 		}
 		return p.parseCommaSepPoints(ss)
@@ -113,9 +255,12 @@ func (p *CommandParser) parseRangePoints(ss *types.SpanSegment) error {
 		return err
 	}
 	p.nextToken()
+	if ss.Right < 0 && p.currentToken != p.job.RosewoodSettings.RangeOperator { //not a step, eg row 2:-1
+		ss.Right = types.FromEnd(-ss.Right - 1)
+	}
 	switch p.currentToken {
 	case p.job.RosewoodSettings.RangeOperator: //another :, so this a skipped range l:step:r
-		if ss.Right == types.RwMax {
+		if types.IsFromEnd(ss.Right) {
 			return fmt.Errorf("max is not allowed in this position")
 		}
 		ss.By = ss.Right //what we thought was the right coordinate is actually a step
@@ -146,7 +291,7 @@ func (p *CommandParser) parseCommaSepPoints(ss *types.SpanSegment) error {
 		if err != nil {
 			return err
 		}
-		if point == types.RwMax {
+		if types.IsFromEnd(point) {
 			return fmt.Errorf("max is not allowed in this position")
 		}
 		ss.List = append(ss.List, point)
@@ -168,13 +313,26 @@ func (p *CommandParser) acceptArg(token rune) string {
 	return p.currentWord()
 }
 
-//parsePoint: reads and validates a row/cell coordinate
+//parsePoint: reads and validates a row/cell coordinate. Coordinates relative to the last row or col are
+//returned as types.FromEnd values: max, max-k and, unless signed, negative numbers where -1 is the last row or col.
+//If signed, negative numbers are returned as is because they may be a range step
 func (p *CommandParser) parsePoint(signed bool) (int, error) {
 	if err := p.nextNotNull(); err != nil {
 		return types.RwMissing, err
 	}
-	if p.currentWord() == "max" {
+	return p.currentPoint(signed)
+}
+
+//currentPoint is like parsePoint but reads the current token
+func (p *CommandParser) currentPoint(signed bool) (int, error) {
+	if word := p.currentWord(); word == "max" {
 		return types.RwMax, nil
+	} else if strings.HasPrefix(word, "max-") { //max-k is scanned as one ident
+		offset, err := strconv.Atoi(word[len("max-"):])
+		if err != nil || offset < 0 {
+			return types.RwMissing, fmt.Errorf("expected col or row number, found %s", p.exactCurrentWord())
+		}
+		return types.FromEnd(offset), nil
 	}
 	var sign rune
 	if p.currentToken == '-' || p.currentToken == '+' {
@@ -183,14 +341,21 @@ func (p *CommandParser) parsePoint(signed bool) (int, error) {
 			return types.RwMissing, err
 		}
 	}
-	if p.currentToken != scanner.Int {
+	coordinate, err := strconv.Atoi(p.currentWord())
+	switch {
+	case p.currentToken == scanner.Int: //no error check as we know it must be an int
+	case p.currentToken == scanner.Ident && err == nil: // '-' is an ident rune so -2 is scanned as an ident
+	default:
 		return types.RwMissing, fmt.Errorf("expected col or row number, found %s", p.exactCurrentWord())
 	}
-	coordinate, _ := strconv.Atoi(p.currentWord()) //no error check as we know it must be an int
 	if sign == '-' {
 		coordinate = -1 * coordinate
 	}
-	if !signed && coordinate < 1 {
+	switch {
+	case signed:
+	case coordinate < 0: //eg row -1 is the last row
+		return types.FromEnd(-coordinate - 1), nil
+	case coordinate == 0:
 		p.addSyntaxError("wanted row/col number > 0; found %s", p.exactCurrentWord()) //keep parsing
 		return types.RwMissing, nil
 	}
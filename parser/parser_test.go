@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
@@ -40,6 +41,8 @@ func TestCommandParser_ParseOneLineCommands(t *testing.T) {
 		{"merge row 1:-2:3", 1, false, "merge row 1:-2:3"},
 		{"style row 1,7 style1 \n", 1, false, "style row 1,7 style1"},
 		{"style row 1,7 col 1 style1 \n", 1, false, "style row 1,7 col 1:NA style1"},
+		{"header row 1:2", 1, false, "header row 1:2"},
+		{"header col 1", 1, false, "header col 1:NA"},
 
 		{"merge row 1:2, 4", 1, false, "merge row 1:2, 4"},
 		{"merge row 1:2:3, 4", 1, false, "merge row 1:2:3, 4"},
@@ -51,6 +54,56 @@ func TestCommandParser_ParseOneLineCommands(t *testing.T) {
 
 		{"merge row 1:max col 2:max", 1, false, "merge row 1:max col 2:max"},
 		{"merge row 1:2:max col 2:2: max", 1, false, "merge row 1:2:max col 2:2:max"},
+		{"style row -1 total", 1, false, "style row max:NA total"},
+		{"style row -3 col max total", 1, false, "style row max-2:NA col max:NA total"},
+		{"merge row max-2:max col 1", 1, false, "merge row max-2:max col 1:NA"},
+		{"merge row 2:-2", 1, false, "merge row 2:max-1"},
+		{`style col "Crude OR (95% CI)" right`, 1, false, `style col "Crude OR (95% CI)" right`},
+		{`style row "Total" col 2 bold`, 1, false, `style row "Total" col 2:NA bold`},
+		{`merge row 1 col "Models":3`, 1, true, "label in a range"},
+		{`merge row "A","B"`, 1, true, "label in a list"},
+		{`style row "  " bold`, 1, true, "empty label"},
+		{"style row 2:max where col 4 < 0.05 significant", 1, false, "style row 2:max where col 4 < 0.05 significant"},
+		{`style col 2:max where text ~ "^0\.0" highlight`, 1, false, `style col 2:max where text ~ "^0\.0" highlight`},
+		{"style row 2 col 1 where col -1 >= -0.5 low x", 1, false, "style row 2:NA col 1:NA where col max >= -0.5 low,x"},
+		{`style row 1 where text != "a \"b\"" x`, 1, false, `style row 1:NA where text != "a \"b\"" x`},
+		{"merge row 1 where text = x", 1, true, "where in merge"},
+		{"style row 1 where text < abc bold", 1, true, "comparing with text"},
+		{"style row 1 where value = 1 bold", 1, true, "invalid subject"},
+		{`style row 1 where text ~ "(" bold`, 1, true, "invalid regexp"},
+		{"style row 1 where text 1 bold", 1, true, "missing operator"},
+		{"style row 1 where text =< 1 bold", 1, true, "invalid operator"},
+		{"style row 2:max except row 5,9 col 2:max shaded", 1, false, "style row 2:max except row 5,9 col 2:max shaded"},
+		{`style col 1:6 except col "Notes" row 1 except row 2:2:6 x`, 1, false, `style col 1:6 except col "Notes" row 1:NA except row 2:2:6 x`},
+		{"merge row 1 col 1:2 union row 3:4 col 3", 1, false, "merge row 1:NA col 1:2 union row 3:4 col 3:NA"},
+		{"style row 1 union col 2 union row 3 col 3 except col 3 bold", 1, false, "style row 1:NA union col 2:NA union row 3:NA col 3:NA except col 3:NA bold"},
+		{"style row 2:max col 2:max except row 5 shaded", 1, true, "except of a different kind"},
+		{"style row 2:max except row 5 except row 6 shaded", 1, true, "duplicate except"},
+		{"style row 2:max except shaded", 1, true, "except without a segment"},
+		{"style row 1 union bold", 1, true, "union without a segment"},
+		{"style row 1 union row 2 row 3 bold", 1, true, "duplicate row in union"},
+		{"style row 3:1 union row 2 bold", 1, true, "invalid range after union"},
+		{"style row 1 union row 3:1 bold", 1, true, "invalid range in union"},
+		{`format col 2:3 decimals 2 thousands ","`, 1, false, `format col 2:3 decimals,2,thousands,","`},
+		{`format row 2:max col 4 where col 1 != "N" pvalue "<0.001"`, 1, false, `format row 2:max col 4:NA where col 1 != "N" pvalue,"<0.001"`},
+		{"format col 3 percent decimals 1", 1, false, "format col 3:NA percent,decimals,1"},
+		{"format col 2", 1, true, "no options"},
+		{"format col 2 decimals", 1, true, "missing value"},
+		{"format col 2 decimals -1", 1, true, "negative decimals"},
+		{"format col 2 bold", 1, true, "invalid option"},
+		{"style col 2 bold 2", 1, true, "value in style"},
+		{"align col 2:3 decimal", 1, false, "align col 2:3 decimal"},
+		{`align row 2:max col 2 on "("`, 1, false, `align row 2:max col 2:NA on,"("`},
+		{"align col 2", 1, true, "missing alignment"},
+		{"align col 2 on", 1, true, "missing alignment char"},
+		{`align col 2 on ""`, 1, true, "empty alignment char"},
+		{"align col 2 center", 1, true, "invalid alignment"},
+		{"merge row 2:-1, 1", 1, true, "max not allowed before a list"},
+		{"merge row max-1:max-2", 1, true, "row numbers invalid"},
+		{"merge row max-2:4", 1, true, "absolute coordinate after a relative one"},
+		{"merge row max-x", 1, true, "invalid relative coordinate"},
+		{"merge row 1:max-1:10", 1, true, "relative coordinate as a step"},
+		{"merge row 1, -1", 1, true, "relative coordinate in a list"},
 		{`set rangeseparator "-"
 			`, 1, false, "set rangeseparator,\"-\""}, //escaping " using \
 		{"merge col 1:2 row 1 ", 1, false, "merge col 1:2 row 1:NA"}, //switched row and col positions
@@ -78,6 +131,7 @@ func TestCommandParser_ParseOneLineCommands(t *testing.T) {
 		{"merge row 1:0:10", 1, true, "zero not allowed as a step"},
 		{"merge row max:1", 1, true, "max not allowed in a left coordinate"},
 		{"merge row 1:max:10", 1, true, "max not allowed as a step"},
+		{"header row 1 bold", 1, true, "header does not take arguments"},
 		{"merge row 1,2,3, max", 1, true, "max not allowed after a range"},
 		{"merge row 1,2,3, max, 4, 5", 1, true, "max not allowed in a range"},
 		{"\n", 1, true, "empty line"},
@@ -90,19 +144,18 @@ func TestCommandParser_ParseOneLineCommands(t *testing.T) {
 		// {`set rangeseparator "-" "onemore"
 		// 	`, 1, true, "invalid # args to set"},
 	}
-	p := NewCommandParser(types.DebugRosewoodSettings(types.DebugAll)) //use default settings
+	p := NewCommandParser(types.DefaultJob(types.DefaultRosewoodSettings())) //use default settings
 	for _, tt := range tests {
 		t.Run(tt.want, func(t *testing.T) {
 			ss := strings.Split(tt.source, "\n")
-			p.trace.Println(strings.Repeat("*", 30))
-			p.trace.Printf("%d %+q\n", len(ss), ss)
+			t.Logf("%d %+q\n", len(ss), ss)
 			got, err := p.ParseCommandLines(types.NewControlSection(ss))
 			//fmt.Println(tt.source)
 			if tt.wantError != (err != nil) {
 				t.Errorf("Error handling failed, wanted %t, got %t\n error: %s", tt.wantError, err != nil, p.ErrorText(-1))
 			}
 			if showErrorMessages && p.errors.Len() > 0 {
-				p.trace.Printf("faulty command %s --> %s\n", strings.TrimSpace(tt.source), p.ErrorText(-1))
+				t.Logf("faulty command %s --> %s\n", strings.TrimSpace(tt.source), p.ErrorText(-1))
 			}
 			if err != nil {
 				return //if error was correctly reported by the parser do not continue testing
@@ -141,7 +194,7 @@ func TestCommandParser_ParseMultiLineCommands(t *testing.T) {
 		merge	row 1:2 col 1:2
 		`, 2, true, ""},
 	}
-	p := NewCommandParser(types.DefaultRosewoodSettings()) //use default settings
+	p := NewCommandParser(types.DefaultJob(types.DefaultRosewoodSettings())) //use default settings
 	for _, tt := range tests {
 		t.Run(tt.source, func(t *testing.T) {
 			ss := strings.Split(tt.source, "\n")
@@ -197,7 +250,7 @@ func TestCommandParser_ParseFullScript(t *testing.T) {
 	}{
 		{"Script 1", script1, 10, false, ""},
 	}
-	p := NewCommandParser(types.DefaultRosewoodSettings()) //use default settings
+	p := NewCommandParser(types.DefaultJob(types.DefaultRosewoodSettings())) //use default settings
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -225,3 +278,94 @@ func TestCommandParser_ParseFullScript(t *testing.T) {
 		})
 	}
 }
+
+func TestCommandParser_ParseUseCommand(t *testing.T) {
+	tests := []struct {
+		source    string
+		length    int
+		wantError bool
+	}{
+		{`use "house-style.rwc"`, 3, false},
+		{`use "house-style.rwc"
+		style row 3 col 1 italic`, 4, false},
+		{`use house-style.rwc`, 0, true},                 //file name must be quoted
+		{`use "house-style.rwc" "another.rwc"`, 0, true}, //only one file per command
+		{`use "missing.rwc"`, 0, true},
+		{`use "circular.rwc"`, 0, true},
+	}
+	p := NewCommandParser(types.DefaultJob(types.DefaultRosewoodSettings())) //use default settings
+	p.baseDir = "../test-files"
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			got, err := p.ParseCommandLines(types.NewControlSection(strings.Split(tt.source, "\n")))
+			if tt.wantError != (err != nil) {
+				t.Errorf("Error handling failed, wanted %t, got %t \n errors %s:", tt.wantError, err != nil, p.ErrorText(-1))
+			}
+			if err != nil {
+				return //if error was correctly reported by the parser do not continue testing
+			}
+			if len(got) != tt.length {
+				t.Errorf("Length of commands is incorrect, wanted %d, got %d", tt.length, len(got))
+			}
+		})
+	}
+}
+
+func TestFile_ParseTableFileName(t *testing.T) {
+	const src = `+++
+Table 1
++++
+%s
++++
++++
+%s
++++
+`
+	tests := []struct {
+		name      string
+		body      string
+		commands  string
+		wantError bool
+		want      []string //text of the cells in the last row
+	}{
+		{"csv replaces empty body", "", `set tablefilename "results.csv"`, false, []string{`Ford "Model T"`, "1\n2", "300"}},
+		{"csv replaces body", "a|b|", `set tablefilename "results.csv"
+style row 1 header`, false, []string{`Ford "Model T"`, "1\n2", "300"}},
+		{"tsv", "", `set tablefilename "results.tsv"`, false, []string{"AMC", "3", "4,215.67"}},
+		{"fixed-width listing", "", `set tablefilename "statins.lst"`, false, []string{"  High", "0.95 (0.87-1.03)", "0.99 (0.92-1.07)"}},
+		{"fixed-width listing with column starts", "", `set columnstarts "1, 60"
+set tablefilename "statins.lst"`, false, []string{"  High                                   0.95 (0.87-1.03)", "0.99 (0.92-1.07)"}},
+		{"invalid column starts", "a|b|", `set columnstarts "1, x"`, true, nil},
+		{"missing file", "", `set tablefilename "missing.csv"`, true, nil},
+		{"separator not found", "", `set tablefilename "hospitals.dat"`, true, nil},
+		{"column starts without separator", "", `set columnstarts "1"
+set tablefilename "hospitals.dat"`, false, []string{"St Mary"}},
+		{"empty body and no table file", "", `style row 1 header`, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFile("../test-files/test.rw", types.DefaultJob(types.DefaultRosewoodSettings()))
+			err := f.Parse(strings.NewReader(fmt.Sprintf(src, tt.body, tt.commands)))
+			if tt.wantError != (err != nil) {
+				t.Fatalf("Error handling failed, wanted %t, got %v", tt.wantError, err)
+			}
+			if err != nil {
+				return
+			}
+			contents := f.Tables()[0].Contents
+			row := contents.Row(contents.RowCount())
+			if row.String() != rowString(contents.RowCount(), tt.want) {
+				t.Errorf("last row = %q, want %q", row.String(), rowString(contents.RowCount(), tt.want))
+			}
+		})
+	}
+}
+
+//rowString formats cell texts the way table.Row.String() does
+func rowString(row int, texts []string) string {
+	var b strings.Builder
+	for i, text := range texts {
+		b.WriteString(fmt.Sprintf("r%d c%d: %s|", row, i+1, text))
+	}
+	return b.String()
+}
@@ -5,7 +5,10 @@ package parser
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/drgo/rosewood/table"
 	"github.com/drgo/rosewood/types"
@@ -22,17 +25,44 @@ func (p *CommandParser) runSetCommand(cmd *types.Command) error {
 	// openFile := func(fileName string) (*os.File, error) {
 	// 	return os.Open(fileName)
 	// }
+	//loadTable loads a table body from a csv, tsv or Rosewood (pipe-delimited) file
 	loadTable := func(fileName string) (*table.TableContents, error) {
-		data, err := ioutil.ReadFile(fileName)
+		if !filepath.IsAbs(fileName) { //relative to the file that contains the set command
+			fileName = filepath.Join(p.baseDir, fileName)
+		}
+		f, err := os.Open(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load table data %s", err)
+		}
+		defer f.Close()
+		switch strings.ToLower(filepath.Ext(fileName)) {
+		case ".csv":
+			return table.NewTableContentsFromCSV(f, ',')
+		case ".tsv", ".tab":
+			return table.NewTableContentsFromCSV(f, '\t')
+		}
+		data, err := ioutil.ReadAll(f)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load table data %s", err)
 		}
-		return table.NewTableContents(string(data))
+		sep := p.job.RosewoodSettings.ColumnSeparator
+		if sep == "" {
+			sep = table.DefaultColumnSeparator
+		}
+		//space-aligned listings eg SAS .lst files have no column separators
+		switch ext := strings.ToLower(filepath.Ext(fileName)); {
+		case ext == ".lst" || ext == ".txt" || len(p.columnStarts) > 0:
+			return table.NewTableContentsFromFixedWidth(string(data), table.FixedWidthOptions{ColumnStarts: p.columnStarts})
+		case !strings.Contains(string(data), sep):
+			return nil, fmt.Errorf("column separator %q not found in table data %s; set columnstarts to load a "+
+				"space-aligned file", sep, fileName)
+		}
+		return table.NewTableContentsWithSeparator(string(data), sep)
 	}
 
 	var s string
 	var err error
-	switch cmd.Arg(0) { //setting name
+	switch cmd.Args()[0] { //setting name; not quoted so cmd.Arg() cannot be used
 	case "rangeseparator":
 		if s, err = getArgAsString(1, 1); err != nil {
 			return err
@@ -56,13 +86,25 @@ func (p *CommandParser) runSetCommand(cmd *types.Command) error {
 		}
 		p.tables = append(p.tables, table)
 		p.job.UI.Logf("%v", table)
+	case "columnstarts": //eg "1, 33, 66"; must precede set tablefilename
+		if s, err = getArgAsString(1, 1); err != nil {
+			return err
+		}
+		p.columnStarts = nil
+		for _, field := range strings.Split(s, ",") {
+			start, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || start < 1 {
+				return fmt.Errorf("invalid column start %s in set columnstarts", field)
+			}
+			p.columnStarts = append(p.columnStarts, start)
+		}
 	case "logfilename":
 		if s, err = getArgAsString(1, 1); err != nil {
 			return err
 		}
 		//		p.job.RosewoodSettings.LogFileName = s //change to method on CommandParser
 	default:
-		return fmt.Errorf("unknown option %s", cmd.Arg(0))
+		return fmt.Errorf("unknown option %s", cmd.Args()[0])
 	}
 	return nil
 }
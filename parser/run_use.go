@@ -0,0 +1,44 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package parser
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/drgo/rosewood/types"
+)
+
+//runUseCommand loads the command file named in a use command and returns its parsed commands
+func (p *CommandParser) runUseCommand(cmd *types.Command) ([]*types.Command, error) {
+	fileName := cmd.Arg(0)
+	if strings.TrimSpace(fileName) == "" {
+		return nil, fmt.Errorf("invalid file name in use command")
+	}
+	if !filepath.IsAbs(fileName) { //relative to the file that contains the use command
+		fileName = filepath.Join(p.baseDir, fileName)
+	}
+	for _, inc := range p.includes {
+		if inc == fileName {
+			return nil, fmt.Errorf("circular use of command file %s", cmd.Arg(0))
+		}
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load command file %s", err)
+	}
+	p.job.UI.Logf("loading commands from %s\n", fileName)
+	//use a separate parser so that errors and line numbers refer to the command file
+	up := NewCommandParser(p.job)
+	up.baseDir = filepath.Dir(fileName)
+	up.includes = append(append(up.includes, p.includes...), fileName)
+	lines := strings.Split(strings.Replace(string(data), "\r\n", "\n", -1), "\n")
+	cmdList, err := up.ParseCommandLines(types.NewControlSection(lines))
+	if err != nil {
+		return nil, fmt.Errorf("in command file %s: %s", cmd.Arg(0), err)
+	}
+	p.tables = append(p.tables, up.tables...) //tables loaded by set tablefilename commands in the command file
+	return cmdList, nil
+}
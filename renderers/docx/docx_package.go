@@ -0,0 +1,288 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package docx
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drgo/rosewood/types"
+)
+
+const (
+	xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+	nsW       = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`
+	nsR       = `xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+	relNS     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+
+	rootRels = xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="` + relNS + `/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+	stylesXML = xmlHeader + `<w:styles ` + nsW + `>
+<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="Calibri" w:hAnsi="Calibri" w:cs="Calibri"/><w:sz w:val="20"/><w:szCs w:val="20"/></w:rPr></w:rPrDefault>
+<w:pPrDefault><w:pPr><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>
+<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:qFormat/></w:style>
+<w:style w:type="paragraph" w:styleId="Caption"><w:name w:val="caption"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>
+<w:pPr><w:spacing w:before="120" w:after="120"/></w:pPr><w:rPr><w:b/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="TableFootnote"><w:name w:val="Table Footnote"/><w:basedOn w:val="Normal"/><w:qFormat/>
+<w:rPr><w:sz w:val="16"/><w:szCs w:val="16"/></w:rPr></w:style>
+<w:style w:type="table" w:default="1" w:styleId="TableNormal"><w:name w:val="Normal Table"/>
+<w:tblPr><w:tblInd w:w="0" w:type="dxa"/><w:tblCellMar><w:top w:w="0" w:type="dxa"/><w:left w:w="108" w:type="dxa"/><w:bottom w:w="0" w:type="dxa"/><w:right w:w="108" w:type="dxa"/></w:tblCellMar></w:tblPr></w:style>
+<w:style w:type="table" w:styleId="RosewoodTable"><w:name w:val="Rosewood Table"/><w:basedOn w:val="TableNormal"/>
+<w:tblPr><w:tblBorders><w:top w:val="single" w:sz="8" w:space="0" w:color="000000"/><w:bottom w:val="single" w:sz="8" w:space="0" w:color="000000"/></w:tblBorders></w:tblPr>
+<w:tblStylePr w:type="firstRow"><w:tcPr><w:tcBorders><w:bottom w:val="single" w:sz="4" w:space="0" w:color="000000"/></w:tcBorders></w:tcPr></w:tblStylePr></w:style>
+</w:styles>`
+
+	settingsXML = xmlHeader + `<w:settings ` + nsW + `><w:evenAndOddHeaders/></w:settings>`
+)
+
+//packageFile holds the name and contents of a file in the docx package
+type packageFile struct {
+	name     string
+	contents string
+}
+
+//headerFooterPart holds info on a header or footer xml part in the package
+type headerFooterPart struct {
+	hf       *types.HeaderFooter
+	hfType   string //default, first or even
+	isFooter bool
+	relID    string
+	fileName string
+}
+
+//writePackage writes a complete docx (zip) package containing body as the document body. body holds the
+//properties of all sections but the last, which are written here
+func writePackage(w io.Writer, body []byte, sections []*types.DocumentSection, sectionParts [][]*headerFooterPart) error {
+	var parts []*headerFooterPart
+	for _, sp := range sectionParts {
+		parts = append(parts, sp...)
+	}
+	evenPages := hasEvenPages(parts)
+	zw := zip.NewWriter(w)
+	files := []packageFile{
+		{"[Content_Types].xml", contentTypes(parts, evenPages)},
+		{"_rels/.rels", rootRels},
+		{"word/_rels/document.xml.rels", documentRels(parts, evenPages)},
+		{"word/styles.xml", stylesXML},
+	}
+	if evenPages { //Word ignores even page headers and footers unless told otherwise
+		files = append(files, packageFile{"word/settings.xml", settingsXML})
+	}
+	for _, part := range parts {
+		files = append(files, packageFile{"word/" + part.fileName, headerFooterXML(part)})
+	}
+	for _, f := range files {
+		if err := writeZipFile(zw, f.name, []byte(f.contents)); err != nil {
+			return err
+		}
+	}
+	var b strings.Builder
+	b.WriteString(xmlHeader + `<w:document ` + nsW + ` ` + nsR + `><w:body>` + "\n")
+	b.Write(body)
+	last := len(sections) - 1
+	b.WriteString(sectionProps(sections[last], sectionParts[last]))
+	b.WriteString("</w:body></w:document>")
+	if err := writeZipFile(zw, "word/document.xml", []byte(b.String())); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to create %s in docx package: %s", name, err)
+	}
+	if _, err = f.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s in docx package: %s", name, err)
+	}
+	return nil
+}
+
+//documentSections returns the sections of the document or a default section if none was specified
+func documentSections(doc *types.Document) []*types.DocumentSection {
+	var sections []*types.DocumentSection
+	if doc != nil {
+		for _, s := range doc.Sections {
+			if s != nil {
+				sections = append(sections, s)
+			}
+		}
+	}
+	if len(sections) == 0 {
+		sections = append(sections, doc.Section())
+	}
+	return sections
+}
+
+//headerFooterParts returns the headers and footers referenced by each section
+func headerFooterParts(doc *types.Document, sections []*types.DocumentSection) [][]*headerFooterPart {
+	parts := make([][]*headerFooterPart, len(sections))
+	count, headers, footers := 0, 0, 0
+	for i, section := range sections {
+		for _, ref := range section.Props.HeadersFooters {
+			if ref == nil {
+				continue
+			}
+			hf := doc.HeaderFooter(ref.ID)
+			if hf == nil {
+				continue
+			}
+			part := &headerFooterPart{hf: hf, hfType: headerFooterType(ref.HFType),
+				isFooter: strings.HasPrefix(strings.ToLower(hf.ID), "footer")}
+			if part.isFooter {
+				footers++
+				part.fileName = "footer" + strconv.Itoa(footers) + ".xml"
+			} else {
+				headers++
+				part.fileName = "header" + strconv.Itoa(headers) + ".xml"
+			}
+			count++
+			part.relID = "rId" + strconv.Itoa(count+1) //rId1 is used by styles.xml
+			parts[i] = append(parts[i], part)
+		}
+	}
+	return parts
+}
+
+//hasEvenPages returns true if any of the parts is an even page header or footer
+func hasEvenPages(parts []*headerFooterPart) bool {
+	for _, part := range parts {
+		if part.hfType == "even" {
+			return true
+		}
+	}
+	return false
+}
+
+func headerFooterType(hfType string) string {
+	switch strings.ToLower(strings.TrimSpace(hfType)) {
+	case "first":
+		return "first"
+	case "even":
+		return "even"
+	default:
+		return "default"
+	}
+}
+
+func contentTypes(parts []*headerFooterPart, evenPages bool) string {
+	const ctPrefix = "application/vnd.openxmlformats-officedocument.wordprocessingml."
+	var b strings.Builder
+	b.WriteString(xmlHeader + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` + "\n")
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` + "\n")
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>` + "\n")
+	b.WriteString(`<Override PartName="/word/document.xml" ContentType="` + ctPrefix + `document.main+xml"/>` + "\n")
+	b.WriteString(`<Override PartName="/word/styles.xml" ContentType="` + ctPrefix + `styles+xml"/>` + "\n")
+	if evenPages {
+		b.WriteString(`<Override PartName="/word/settings.xml" ContentType="` + ctPrefix + `settings+xml"/>` + "\n")
+	}
+	for _, part := range parts {
+		kind := "header"
+		if part.isFooter {
+			kind = "footer"
+		}
+		b.WriteString(`<Override PartName="/word/` + part.fileName + `" ContentType="` + ctPrefix + kind + `+xml"/>` + "\n")
+	}
+	b.WriteString("</Types>")
+	return b.String()
+}
+
+func documentRels(parts []*headerFooterPart, evenPages bool) string {
+	var b strings.Builder
+	b.WriteString(xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + "\n")
+	b.WriteString(`<Relationship Id="rId1" Type="` + relNS + `/styles" Target="styles.xml"/>` + "\n")
+	if evenPages { //header and footer ids are numbered from rId2
+		b.WriteString(`<Relationship Id="rIdSettings" Type="` + relNS + `/settings" Target="settings.xml"/>` + "\n")
+	}
+	for _, part := range parts {
+		kind := "header"
+		if part.isFooter {
+			kind = "footer"
+		}
+		b.WriteString(`<Relationship Id="` + part.relID + `" Type="` + relNS + "/" + kind + `" Target="` + part.fileName + `"/>` + "\n")
+	}
+	b.WriteString("</Relationships>")
+	return b.String()
+}
+
+func headerFooterXML(part *headerFooterPart) string {
+	tag := "w:hdr"
+	if part.isFooter {
+		tag = "w:ftr"
+	}
+	var b strings.Builder
+	b.WriteString(xmlHeader + "<" + tag + " " + nsW + " " + nsR + ">")
+	for _, line := range strings.Split(part.hf.Contents, "\n") {
+		b.WriteString("<w:p>" + expandPlaceholders(line) + "</w:p>")
+	}
+	b.WriteString("</" + tag + ">")
+	return b.String()
+}
+
+//placeholderRe matches ${word: FIELD} and ${htmldocx: timestamp} placeholders
+var placeholderRe = regexp.MustCompile(`\$\{\s*(word|htmldocx)\s*:\s*([^}]*?)\s*\}`)
+
+//expandPlaceholders converts header/footer text into runs replacing placeholders with Word fields or values
+func expandPlaceholders(s string) string {
+	textRun := func(text string) string {
+		if text == "" {
+			return ""
+		}
+		return `<w:r><w:t xml:space="preserve">` + escapeString(text) + "</w:t></w:r>"
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range placeholderRe.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(textRun(s[last:m[0]]))
+		kind, value := s[m[2]:m[3]], s[m[4]:m[5]]
+		switch {
+		case kind == "word": //eg PAGE or NUMPAGES
+			b.WriteString(`<w:fldSimple w:instr=" ` + escapeString(value) + ` "><w:r><w:t>1</w:t></w:r></w:fldSimple>`)
+		case strings.EqualFold(value, "timestamp"):
+			b.WriteString(textRun(time.Now().Format("2006-01-02 15:04:05")))
+		default: //unknown placeholders are written as is
+			b.WriteString(textRun(s[m[0]:m[1]]))
+		}
+		last = m[1]
+	}
+	b.WriteString(textRun(s[last:]))
+	return b.String()
+}
+
+//sectionProps returns the w:sectPr element of a section
+func sectionProps(section *types.DocumentSection, parts []*headerFooterPart) string {
+	var b strings.Builder
+	b.WriteString("<w:sectPr>")
+	for _, part := range parts {
+		kind := "headerReference"
+		if part.isFooter {
+			kind = "footerReference"
+		}
+		b.WriteString(`<w:` + kind + ` w:type="` + part.hfType + `" r:id="` + part.relID + `"/>`)
+	}
+	size, m := section.Props.Size, section.Props.Margins
+	width, height := pageDimensions(size)
+	b.WriteString(`<w:pgSz w:w="` + strconv.Itoa(width) + `" w:h="` + strconv.Itoa(height) + `"`)
+	if width > height {
+		b.WriteString(` w:orient="landscape"`)
+	}
+	b.WriteString("/>")
+	b.WriteString(fmt.Sprintf(`<w:pgMar w:top="%d" w:right="%d" w:bottom="%d" w:left="%d" w:header="%d" w:footer="%d" w:gutter="%d"/>`,
+		m.Top, m.Right, m.Bottom, m.Left, m.Header, m.Footer, m.Gutter))
+	for _, part := range parts {
+		if part.hfType == "first" {
+			b.WriteString("<w:titlePg/>") //needed for a different first page header/footer
+			break
+		}
+	}
+	b.WriteString("</w:sectPr>")
+	return b.String()
+}
@@ -0,0 +1,386 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+//Package docx implements a table.Renderer that writes Rosewood tables as native Word (docx) tables
+package docx
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/drgo/rosewood"
+	"github.com/drgo/rosewood/renderers/internal/inline"
+	"github.com/drgo/rosewood/table"
+	"github.com/drgo/rosewood/types"
+)
+
+const (
+	//indentWidth is the width of an indent level in twentieths of a point
+	indentWidth = 284
+
+	//digitWidth is the approximate width of a digit in the default font in twentieths of a point
+	digitWidth = 102
+
+	//cellMargins is the sum of the left and right margins of a cell set in the table style
+	cellMargins = 216
+)
+
+//init, run automatically, registers DOCX renderer with Rosewood
+func init() {
+	config := rosewood.RendererConfig{
+		Name:     "docx",
+		Renderer: makeDOCXRenderer,
+	}
+	rosewood.RegisterRenderer(&config)
+}
+
+//docxRenderer implements table.Renderer for DOCX output
+type docxRenderer struct {
+	bw        io.Writer
+	settings  *types.RosewoodSettings
+	document  *types.Document
+	styles    *types.StyleRegistry
+	tables    []*table.Table
+	docxError error         //tracks errors
+	body      bytes.Buffer  //holds the contents of the body of word/document.xml
+	rowCells  []*table.Cell //cells of the current row; buffered b/c vMerge cells need the width of the row span
+	colCount  int           //number of grid columns in the current table
+	colWidth  int           //width of each grid column of the current table
+
+	sections []*types.DocumentSection //sections of the document, see SetDocument
+	parts    [][]*headerFooterPart    //headers and footers referenced by each section
+
+	tableBodies [][]byte //body of each table rendered by a clone, in table order; shared by all clones
+	clone       bool     //renders tables into tableBodies rather than writing a package
+}
+
+//makeDOCXRenderer factory function according to the renderer registration requirements
+func makeDOCXRenderer() (table.Renderer, error) {
+	return NewDOCXRenderer()
+}
+
+//NewDOCXRenderer create a new docxRenderer and return it as a Renderer
+func NewDOCXRenderer() (table.Renderer, error) {
+	return &docxRenderer{document: types.DefaultDocument(), styles: types.DefaultStyleRegistry()}, nil
+}
+
+func (dr *docxRenderer) SetWriter(w io.Writer) error {
+	dr.bw = w
+	return nil
+}
+
+func (dr *docxRenderer) SetSettings(settings *types.RosewoodSettings) error {
+	dr.settings = settings
+	var err error
+	dr.styles, err = types.LoadStyleRegistry(settings.StylesFileName)
+	return err
+}
+
+//SetDocument sets the page settings; implements table.DocumentSetter. With several sections, the nth section
+//holds the nth table and the last section holds all remaining tables, eg a landscape section for a wide table
+func (dr *docxRenderer) SetDocument(doc *types.Document) error {
+	if doc != nil {
+		dr.document = doc
+	}
+	return nil
+}
+
+func (dr *docxRenderer) SetTables(tables []*table.Table) error {
+	dr.tables = tables
+	return nil
+}
+
+func (dr *docxRenderer) Err() error {
+	return dr.docxError
+}
+
+//Clone implements table.Cloner. A clone keeps the body of each table it renders in the list of table bodies
+//shared with the original, which joins them in table order when it writes the package in EndFile
+func (dr *docxRenderer) Clone() (table.Renderer, error) {
+	if dr.tableBodies == nil {
+		dr.tableBodies = make([][]byte, len(dr.tables))
+	}
+	return &docxRenderer{settings: dr.settings, document: dr.document, styles: dr.styles, tables: dr.tables,
+		sections: dr.sections, parts: dr.parts, tableBodies: dr.tableBodies, clone: true}, nil
+}
+
+//write appends to the document body; errors are only possible when the package is written in EndFile
+func (dr *docxRenderer) write(s string) error {
+	if dr.docxError == nil {
+		dr.body.WriteString(s)
+	}
+	return dr.docxError
+}
+
+func (dr *docxRenderer) StartFile() error {
+	dr.sections = documentSections(dr.document)
+	if len(dr.sections) > max(len(dr.tables), 1) {
+		dr.docxError = fmt.Errorf("document has %d sections but the file has only %d tables", len(dr.sections),
+			len(dr.tables))
+		return dr.docxError
+	}
+	dr.parts = headerFooterParts(dr.document, dr.sections)
+	dr.tableBodies = nil
+	dr.body.Reset()
+	dr.body.Grow(1024 * 100) //preallocate 100kb to avoid additional allocations
+	return dr.Err()
+}
+
+func (dr *docxRenderer) EndFile() error {
+	if dr.docxError != nil {
+		return dr.docxError
+	}
+	for _, body := range dr.tableBodies { //tables rendered concurrently by clones
+		dr.body.Write(body)
+	}
+	dr.docxError = writePackage(dr.bw, dr.body.Bytes(), dr.sections, dr.parts)
+	return dr.docxError
+}
+
+func (dr *docxRenderer) StartTable(t *table.Table) error {
+	if dr.clone {
+		dr.body.Reset()
+	}
+	if t.Caption != nil {
+		for _, line := range t.Caption.Lines {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			dr.write(`<w:p><w:pPr><w:pStyle w:val="Caption"/><w:keepNext/></w:pPr>` + dr.renderText(line, types.Style{}) + "</w:p>\n")
+		}
+	}
+	dr.colCount = t.ProcessedTableContents().MaxFieldCount()
+	var b strings.Builder
+	b.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="RosewoodTable"/><w:tblW w:w="5000" w:type="pct"/>`)
+	b.WriteString(`<w:tblLook w:val="04A0" w:firstRow="1" w:lastRow="0" w:firstColumn="1" w:lastColumn="0" w:noHBand="0" w:noVBand="1"/></w:tblPr>`)
+	b.WriteString("<w:tblGrid>")
+	dr.colWidth = textWidth(dr.sections[dr.sectionOf(t)]) / max(dr.colCount, 1)
+	for i := 0; i < dr.colCount; i++ {
+		b.WriteString(`<w:gridCol w:w="` + strconv.Itoa(dr.colWidth) + `"/>`)
+	}
+	b.WriteString("</w:tblGrid>\n")
+	dr.write(b.String())
+	return dr.Err()
+}
+
+func (dr *docxRenderer) EndTable(t *table.Table) error {
+	dr.write("</w:tbl>\n")
+	if t.Footnotes != nil {
+		for _, line := range t.Footnotes.Lines {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			dr.write(`<w:p><w:pPr><w:pStyle w:val="TableFootnote"/></w:pPr>` + dr.renderText(line, types.Style{}) + "</w:p>\n")
+		}
+	}
+	if i := dr.sectionOf(t); i < len(dr.sections)-1 { //the last paragraph of a section holds its properties
+		dr.write("<w:p><w:pPr>" + sectionProps(dr.sections[i], dr.parts[i]) + "</w:pPr></w:p>\n")
+	} else {
+		dr.write("<w:p/>\n") //separates consecutive tables, otherwise Word joins them
+	}
+	if dr.clone && dr.docxError == nil {
+		if i := table.IndexOf(dr.tables, t); i >= 0 {
+			dr.tableBodies[i] = append([]byte(nil), dr.body.Bytes()...)
+		}
+	}
+	return dr.Err()
+}
+
+//sectionOf returns the index of the section that holds table t, see SetDocument
+func (dr *docxRenderer) sectionOf(t *table.Table) int {
+	if i := table.IndexOf(dr.tables, t); i >= 0 && i < len(dr.sections) {
+		return i
+	}
+	return len(dr.sections) - 1
+}
+
+func (dr *docxRenderer) StartRow(r *table.Row) error {
+	dr.rowCells = dr.rowCells[:0]
+	return dr.Err()
+}
+
+//EndRow writes the buffered cells of the row
+func (dr *docxRenderer) EndRow(r *table.Row) error {
+	var b strings.Builder
+	b.Grow(1024)
+	b.WriteString("<w:tr>")
+	if r.Head() || r.IsHeader() { //set by header row commands or header styles
+		b.WriteString("<w:trPr><w:tblHeader/></w:trPr>") //repeat header rows on each page
+	}
+	for i, c := range dr.rowCells {
+		switch c.State() {
+		case table.CsHMerged: //covered by the gridSpan of a cell to its left
+			continue
+		case table.CsVMerged, table.CsVHMerged: //continuation of a vertical merge
+			b.WriteString("<w:tc><w:tcPr>")
+			if span := r.SpanWidth(i + 1); span > 1 {
+				b.WriteString(`<w:gridSpan w:val="` + strconv.Itoa(span) + `"/>`)
+			}
+			b.WriteString("<w:vMerge/></w:tcPr><w:p/></w:tc>")
+			continue
+		}
+		style := dr.styles.Resolve(c.Styles())
+		if c.Header() {
+			style.Weight = "bold"
+		}
+		b.WriteString("<w:tc><w:tcPr>")
+		if c.ColSpan() > 1 {
+			b.WriteString(`<w:gridSpan w:val="` + strconv.Itoa(c.ColSpan()) + `"/>`) //eg gridSpan="2"
+		}
+		if c.RowSpan() > 1 {
+			b.WriteString(`<w:vMerge w:val="restart"/>`)
+		}
+		b.WriteString(cellProps(style))
+		b.WriteString("</w:tcPr><w:p>")
+		before, after, bw, aw, aligned := c.Aligned()
+		tabPos := 0
+		if aligned { //a right tab stop at the alignment point; the widest parts of the column are centred
+			width := dr.colWidth*max(c.ColSpan(), 1) - cellMargins
+			tabPos = max((width-(bw+aw)*digitWidth)/2, 0) + bw*digitWidth
+		}
+		if pPr := cellParagraphProps(c, style, tabPos); pPr != "" {
+			b.WriteString("<w:pPr>" + pPr + "</w:pPr>")
+		}
+		if aligned {
+			b.WriteString("<w:r><w:tab/></w:r>" + dr.renderText(before, style) + dr.renderText(after, style))
+		} else {
+			b.WriteString(dr.renderText(strings.TrimSpace(c.Text()), style))
+		}
+		b.WriteString("</w:p></w:tc>")
+	}
+	b.WriteString("</w:tr>\n")
+	return dr.write(b.String())
+}
+
+func (dr *docxRenderer) OutputCell(c *table.Cell) error {
+	dr.rowCells = append(dr.rowCells, c)
+	return dr.Err()
+}
+
+//renderText converts a line of text into one or more Word runs formatted according to the cell style
+func (dr *docxRenderer) renderText(s string, style types.Style) string {
+	var runs []inline.Run
+	if dr.settings != nil && dr.settings.MarkdownRender == "disabled" {
+		runs = []inline.Run{{Text: s}}
+	} else {
+		runs = inline.Parse(s)
+	}
+	var b strings.Builder
+	for _, r := range runs {
+		b.WriteString("<w:r>")
+		if rPr := runProps(r, style); rPr != "" {
+			b.WriteString("<w:rPr>" + rPr + "</w:rPr>")
+		}
+		b.WriteString(`<w:t xml:space="preserve">` + escapeString(r.Text) + "</w:t></w:r>")
+	}
+	return b.String()
+}
+
+//textWidth returns the width of the page of a section between the margins
+func textWidth(section *types.DocumentSection) int {
+	p := section.Props
+	width, _ := pageDimensions(p.Size)
+	if w := width - p.Margins.Left - p.Margins.Right - p.Margins.Gutter; w > 0 {
+		return w
+	}
+	return 9360 //letter size with 1-inch margins
+}
+
+func runProps(r inline.Run, style types.Style) string {
+	var b strings.Builder
+	if style.Bold() || r.Bold {
+		b.WriteString("<w:b/>")
+	}
+	if style.Italic || r.Italic {
+		b.WriteString("<w:i/>")
+	}
+	if r.Strike {
+		b.WriteString("<w:strike/>")
+	}
+	if r.Code {
+		b.WriteString(`<w:rFonts w:ascii="Courier New" w:hAnsi="Courier New"/>`)
+	}
+	switch {
+	case r.Superscript:
+		b.WriteString(`<w:vertAlign w:val="superscript"/>`)
+	case r.Subscript:
+		b.WriteString(`<w:vertAlign w:val="subscript"/>`)
+	}
+	return b.String()
+}
+
+//cellParagraphProps returns the paragraph properties for the indentation and alignment of a cell. Cells
+//aligned by an align command have a right tab stop at tabPos instead of the alignment of their style
+func cellParagraphProps(c *table.Cell, style types.Style, tabPos int) string {
+	var b strings.Builder
+	if tabPos > 0 { //in the order required by the schema: w:tabs, w:ind, w:jc
+		b.WriteString(`<w:tabs><w:tab w:val="right" w:pos="` + strconv.Itoa(tabPos) + `"/></w:tabs>`)
+	}
+	if indent := style.Indent + c.Indent(); indent > 0 {
+		b.WriteString(`<w:ind w:left="` + strconv.Itoa(indentWidth*indent) + `"/>`)
+	}
+	if tabPos == 0 && (style.Align == "center" || style.Align == "right") {
+		b.WriteString(`<w:jc w:val="` + style.Align + `"/>`)
+	}
+	return b.String()
+}
+
+//cellProps returns the cell properties for the borders and shading of a cell
+func cellProps(style types.Style) string {
+	var b strings.Builder
+	borders := []struct{ side, value string }{{"top", style.BorderTop}, {"left", style.BorderLeft},
+		{"bottom", style.BorderBottom}, {"right", style.BorderRight}} //in the order required by the schema
+	for _, border := range borders {
+		switch border.value {
+		case "":
+			continue
+		case "none":
+			b.WriteString(`<w:` + border.side + ` w:val="nil"/>`)
+		default:
+			size := "4" //in eighths of a point
+			if border.value == "thick" {
+				size = "12"
+			}
+			b.WriteString(`<w:` + border.side + ` w:val="` + border.value + `" w:sz="` + size + `" w:space="0" w:color="auto"/>`)
+		}
+	}
+	if b.Len() > 0 {
+		s := "<w:tcBorders>" + b.String() + "</w:tcBorders>"
+		b.Reset()
+		b.WriteString(s)
+	}
+	if style.Shading != "" {
+		b.WriteString(`<w:shd w:val="clear" w:color="auto" w:fill="` + style.Shading + `"/>`)
+	}
+	return b.String()
+}
+
+//pageDimensions returns the page width and height swapped if needed to match the page orientation
+func pageDimensions(size types.PageSize) (width, height int) {
+	width, height = size.Width, size.Height
+	if strings.EqualFold(size.Orientation, "landscape") != (width > height) {
+		width, height = height, width
+	}
+	return width, height
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+//escapeString escapes characters that are not allowed in xml text
+func escapeString(s string) string {
+	return xmlEscaper.Replace(s)
+}
+
+var xmlEscaper = strings.NewReplacer(
+	`&`, "&amp;",
+	`<`, "&lt;",
+	`>`, "&gt;",
+	`"`, "&quot;",
+)
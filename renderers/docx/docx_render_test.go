@@ -0,0 +1,275 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/drgo/rosewood"
+	"github.com/drgo/rosewood/types"
+)
+
+const mergedTab = `+++
+Table 1. **Merged** cells
++++
+Brand|Some numbers||
+|Models|Price|
+AMC|3|4,215.67|
++++
+Prices in US$
++++
+merge row 1:2 col 1
+merge row 1 col 2:3
+style row 1:2 header
++++
+`
+
+const headerRowsTab = `+++
+Table 3
++++
+Brand|Models|
+|Number|
+AMC|3|
++++
++++
+header row 1:2
++++
+`
+
+const styledTab = `+++
+Table 2
++++
+Brand|Models|Price|
+AMC|3|4,215.67|
+Rambler|2|3,799.00|
++++
++++
+style row 2 italic shaded border-top
+style col 3 right
+style row 3 col 1 indent
+style row 3 col 3 bold left
++++
+`
+
+const alignedTab = `+++
+Table 4
++++
+Outcome|OR|
+Stroke|1.5|
+MI|12.25|
++++
++++
+align col 2 decimal
++++
+`
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{"merged cells", mergedTab, []string{
+			`<w:gridSpan w:val="2"/>`,
+			`<w:vMerge w:val="restart"/>`,
+			`<w:vMerge/>`,
+			`<w:tblHeader/>`,
+			`<w:pStyle w:val="Caption"/>`,
+			`<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Merged</w:t></w:r>`,
+			`<w:pStyle w:val="TableFootnote"/>`,
+			`<w:pgSz w:w="12240" w:h="15840"/>`,
+		}},
+		{"styled cells", styledTab, []string{
+			`<w:top w:val="single" w:sz="4" w:space="0" w:color="auto"/>`,
+			`<w:shd w:val="clear" w:color="auto" w:fill="D9D9D9"/>`,
+			`<w:rPr><w:i/></w:rPr>`,
+			`<w:jc w:val="right"/>`,
+			`<w:ind w:left="284"/>`,
+			`<w:rPr><w:b/></w:rPr><w:t xml:space="preserve">3,799.00</w:t>`,
+		}},
+		{"aligned cells", alignedTab, []string{
+			//half of the 4464 twips between the cell margins less 5 digits plus the 2 digits before the point
+			`<w:pPr><w:tabs><w:tab w:val="right" w:pos="2181"/></w:tabs></w:pPr><w:r><w:tab/></w:r>` +
+				`<w:r><w:t xml:space="preserve">1</w:t></w:r><w:r><w:t xml:space="preserve">.5</w:t></w:r>`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := rosewood.DefaultJob(rosewood.DefaultSettings())
+			ri := rosewood.NewInterpreter(job)
+			file, err := ri.Parse(strings.NewReader(tt.src), tt.name)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			dr, _ := NewDOCXRenderer()
+			w := &bytes.Buffer{}
+			if err := ri.Render(w, file, dr); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			doc := readPart(t, w.Bytes(), "word/document.xml")
+			for _, want := range tt.want {
+				if !strings.Contains(doc, want) {
+					t.Errorf("wanted string [%s] was not found", want)
+				}
+			}
+		})
+	}
+}
+
+func TestHeaderRowsAndSections(t *testing.T) {
+	tests := []struct {
+		name        string
+		sections    int
+		wantHeaders int //number of rows repeated on each page
+		wantErr     string
+	}{
+		{"header row command", 1, 2, ""},
+		{"more sections than tables", 2, 0, "document has 2 sections but the file has only 1 tables"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := rosewood.DefaultJob(rosewood.DefaultSettings())
+			for len(job.Document.Sections) < tt.sections {
+				section := *job.Document.Sections[0]
+				job.Document.Sections = append(job.Document.Sections, &section)
+			}
+			ri := rosewood.NewInterpreter(job)
+			file, err := ri.Parse(strings.NewReader(headerRowsTab), tt.name)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			dr, _ := NewDOCXRenderer()
+			w := &bytes.Buffer{}
+			err = ri.Render(w, file, dr)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Render() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			doc := readPart(t, w.Bytes(), "word/document.xml")
+			if got := strings.Count(doc, "<w:tblHeader/>"); got != tt.wantHeaders {
+				t.Errorf("Render() wrote %d repeated header rows, want %d", got, tt.wantHeaders)
+			}
+		})
+	}
+}
+
+const twoTables = `+++
+Table 1
++++
+a|b|
++++
++++
++++
+Table 2. Wide
++++
+c|d|
++++
++++
++++
+`
+
+func TestSections(t *testing.T) {
+	job := rosewood.DefaultJob(rosewood.DefaultSettings())
+	first := job.Document.Sections[0]
+	first.Props.HeadersFooters = []*types.SectionHeaderFooter{{ID: "header1", HFType: "even"}}
+	wide := *first
+	wide.Props.HeadersFooters = nil
+	wide.Props.Size.Orientation = "landscape"
+	job.Document.Sections = append(job.Document.Sections, &wide)
+	job.Document.HeadersFooters = []*types.HeaderFooter{{ID: "header1", Contents: "Draft"}}
+	ri := rosewood.NewInterpreter(job)
+	file, err := ri.Parse(strings.NewReader(twoTables), "sections")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	dr, _ := NewDOCXRenderer()
+	w := &bytes.Buffer{}
+	if err := ri.Render(w, file, dr); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	doc := readPart(t, w.Bytes(), "word/document.xml")
+	for _, want := range []string{
+		//the first section ends with the paragraph after the first table
+		`</w:tbl>
+<w:p><w:pPr><w:sectPr><w:headerReference w:type="even" r:id="rId2"/><w:pgSz w:w="12240" w:h="15840"/>`,
+		`<w:gridCol w:w="4680"/>`, //half of the portrait text width
+		`<w:gridCol w:w="6480"/>`, //half of the landscape text width
+		`<w:sectPr><w:pgSz w:w="15840" w:h="12240" w:orient="landscape"/>`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("wanted string [%s] was not found in\n%s", want, doc)
+		}
+	}
+	if got := strings.Count(doc, "<w:sectPr>"); got != 2 {
+		t.Errorf("Render() wrote %d section properties, want 2", got)
+	}
+	if settings := readPart(t, w.Bytes(), "word/settings.xml"); !strings.Contains(settings, "<w:evenAndOddHeaders/>") {
+		t.Errorf("settings.xml = %s, want even and odd headers enabled", settings)
+	}
+}
+
+func TestConcurrentRendering(t *testing.T) {
+	var docs []string
+	for _, workers := range []int{1, 2} {
+		settings := rosewood.DefaultSettings()
+		settings.MaxConcurrentWorkers = workers
+		ri := rosewood.NewInterpreter(rosewood.DefaultJob(settings))
+		file, err := ri.Parse(strings.NewReader(twoTables), "concurrent")
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		dr, _ := NewDOCXRenderer()
+		w := &bytes.Buffer{}
+		if err := ri.Render(w, file, dr); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		docs = append(docs, readPart(t, w.Bytes(), "word/document.xml"))
+	}
+	if docs[0] != docs[1] || !strings.Contains(docs[1], "Table 2. Wide") {
+		t.Errorf("concurrent rendering wrote\n%s\nwant\n%s", docs[1], docs[0])
+	}
+}
+
+//readPart returns the contents of the named part after checking that all xml parts are well-formed
+func readPart(t *testing.T, data []byte, name string) string {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("output is not a valid zip file: %v", err)
+	}
+	var found string
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		contents, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		dec := xml.NewDecoder(bytes.NewReader(contents))
+		for {
+			if _, err := dec.Token(); err != nil {
+				if err != io.EOF {
+					t.Errorf("%s is not well-formed xml: %v", f.Name, err)
+				}
+				break
+			}
+		}
+		if f.Name == name {
+			found = string(contents)
+		}
+	}
+	if found == "" {
+		t.Fatalf("%s not found in docx package", name)
+	}
+	return found
+}
@@ -4,14 +4,17 @@ package html
 
 import (
 	"fmt"
+	"html/template"
 	"io"
 	"io/ioutil"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
-	"github.com/drgo/core/files"
 	"github.com/drgo/core/md"
 	"github.com/drgo/core/ui"
 	"github.com/drgo/rosewood"
@@ -20,25 +23,13 @@ import (
 )
 
 const (
-	htmlHeader = `<!DOCTYPE html>
-<head>
-<meta charset="utf-8">
-<meta name="generator" content="Rosewood Carpenter" />
-`
-
-	htmlBody = `	
-</head>
-<body>
-`
-	htmlFooter = `
-</body>
-</html>
-`
-
-	defaultCSSFileName = "carpenter.css"
-)
+	//fragmentClass is the class of the div that wraps an html fragment with scoped css
+	fragmentClass = "rw-fragment"
 
-var defaultCSS []byte
+	//alignedSpan holds one part of a cell aligned by an align command; the rw-align-before and rw-align-after
+	//classes of the theme lay the parts out. Only the width, in ch (the width of a digit), depends on the column
+	alignedSpan = `<span class="rw-align-%s" style="min-width:%dch">%s</span>`
+)
 
 //init, run automatically, registers HTML renderer with Rosewood
 func init() {
@@ -46,11 +37,6 @@ func init() {
 		Name:     "html",
 		Renderer: makeHTMLRenderer,
 	}
-	// load default css once per exe run
-	exeDir, err := files.GetExeDir()
-	if err == nil {
-		defaultCSS, _ = ioutil.ReadFile(filepath.Join(exeDir, defaultCSSFileName))
-	}
 	rosewood.RegisterRenderer(&config)
 }
 
@@ -61,6 +47,20 @@ type htmlRenderer struct {
 	tables    []*table.Table
 	htmlError error  //tracks errors
 	css       []byte //holds css text
+	cssHref   string //url of a linked stylesheet; css is inlined if empty
+	tableNum  int    //number of the current table; used to create unique ids for header cells
+	idPrefix  string //prefix of the ids of header cells, see cellID
+	section   string //currently open row group: thead, tbody or "" if the table has no header rows
+	complex   bool   //current table has complex headers that need id/headers attributes
+
+	cssName  string          //name of the loaded stylesheet, used in warnings
+	classes  map[string]bool //class selectors defined in the loaded stylesheet; nil if none was loaded
+	warnings []string        //style names used in style commands but not defined in the stylesheet
+
+	current  *table.Table       //table being rendered, passed to the cell template
+	layout   *template.Template //page and cell templates, see defaultLayout
+	job      *types.Job         //job metadata passed to the page templates; nil if unknown
+	fileName string             //name of the rendered Rosewood file
 }
 
 //makeHTMLRenderer factory function according to the renderer registration requirements
@@ -80,30 +80,180 @@ func (hr *htmlRenderer) SetWriter(w io.Writer) error {
 
 func (hr *htmlRenderer) SetSettings(settings *types.RosewoodSettings) error {
 	hr.settings = settings
+	var err error
+	if hr.layout, err = loadLayout(settings.HTMLTemplateName); err != nil {
+		return err
+	}
 	cssFileName := strings.TrimSpace(hr.settings.StyleSheetName)
-	if cssFileName == "" { // use default css
-		hr.css = defaultCSS
+	hr.css, hr.cssHref, hr.classes = nil, "", nil
+	if cssFileName == "" { //use a theme; built-in themes are always inlined because they have no file to link to
+		theme := strings.TrimSpace(hr.settings.Theme)
+		if theme == "" {
+			theme = defaultTheme
+		}
+		css, fileName, err := loadTheme(theme, hr.settings.ThemePath)
+		if err != nil {
+			return err
+		}
+		hr.css, hr.cssName, hr.classes = css, "theme "+theme, cssClasses(css)
+		if fileName != "" {
+			hr.cssName = fileName
+			if hr.settings.DoNotInlineCSS {
+				hr.cssHref = fileName
+			}
+		}
 		return nil
 	}
-	hr.css = []byte(cssFileName)
-	var err error
-	if hr.settings.DoNotInlineCSS == false {
-		if hr.css, err = ioutil.ReadFile(cssFileName); err != nil {
-			return fmt.Errorf("failed to load css file %s, %s", cssFileName, err)
+	if hr.settings.DoNotInlineCSS {
+		if hr.settings.HTMLFragment && hr.settings.ScopedCSS {
+			return fmt.Errorf("cannot scope the linked css file %s; inline it or use a theme", cssFileName)
 		}
+		hr.cssHref = cssFileName
+		return nil
+	}
+	if hr.css, err = ioutil.ReadFile(cssFileName); err != nil {
+		return fmt.Errorf("failed to load css file %s, %s", cssFileName, err)
 	}
+	hr.cssName, hr.classes = cssFileName, cssClasses(hr.css)
+	return nil
+}
+
+//SetJob implements table.JobSetter; the job and file name are passed to the page templates
+func (hr *htmlRenderer) SetJob(job *types.Job, fileName string) error {
+	hr.job, hr.fileName = job, fileName
 	return nil
 }
 
 func (hr *htmlRenderer) SetTables(tables []*table.Table) error {
 	hr.tables = tables
+	hr.warnings = hr.checkStyles(tables)
 	return nil
 }
 
+//Warnings implements table.Warner
+func (hr *htmlRenderer) Warnings() []string {
+	return hr.warnings
+}
+
+//checkStyles returns a warning for each style name used in a style command that is not a class defined in the
+//loaded stylesheet, eg a misspelt name. Nothing is checked if no stylesheet was loaded
+func (hr *htmlRenderer) checkStyles(tables []*table.Table) []string {
+	if hr.classes == nil {
+		return nil
+	}
+	var warnings []string
+	for _, t := range tables {
+		for _, cmd := range t.CmdList {
+			if cmd.ID() != types.KwStyle {
+				continue
+			}
+			for _, style := range cmd.Args() {
+				if style == "header" || hr.classes[style] { //header cells are marked up as th
+					continue
+				}
+				warnings = append(warnings, fmt.Sprintf("line %d: style %s is not defined in %s", cmd.Line(), style,
+					hr.cssName))
+			}
+		}
+	}
+	return warnings
+}
+
+//cssClasses returns the class names used in the selectors of css, eg total and rw-indent-1 in
+//td.total, .rw-indent-1 {padding-left: 1em}. Selectors nested in at-rules such as @media are included
+func cssClasses(css []byte) map[string]bool {
+	classes := make(map[string]bool)
+	s := cssComment.ReplaceAllString(string(css), "")
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{': //s[start:i] is a selector or an at-rule prelude
+			if selector := strings.TrimSpace(s[start:i]); !strings.HasPrefix(selector, "@") {
+				for _, m := range cssClass.FindAllStringSubmatch(selector, -1) {
+					classes[m[1]] = true
+				}
+			}
+			start = i + 1
+		case '}', ';':
+			start = i + 1
+		}
+	}
+	return classes
+}
+
+//scopeCSS returns css with its selectors limited to the descendants of the element matched by scope, eg
+//td.total becomes .rw-fragment td.total. Rules for html and body apply to the scope element itself. Rules
+//nested in @media and @supports are scoped; other at-rules (eg @font-face and @keyframes) are kept as is
+func scopeCSS(css []byte, scope string) string {
+	var b strings.Builder
+	s := cssComment.ReplaceAllString(string(css), "")
+	var hasRules []bool //for each open block, true if it holds rules rather than declarations
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			prelude := s[start:i]
+			inRules := len(hasRules) == 0 || hasRules[len(hasRules)-1]
+			trimmed := strings.TrimSpace(prelude)
+			switch {
+			case strings.HasPrefix(trimmed, "@media") || strings.HasPrefix(trimmed, "@supports"):
+				b.WriteString(prelude)
+				hasRules = append(hasRules, true)
+			case inRules && !strings.HasPrefix(trimmed, "@"):
+				b.WriteString(prelude[:len(prelude)-len(strings.TrimLeft(prelude, " \t\r\n"))]) //keep the layout
+				b.WriteString(scopeSelectors(trimmed, scope) + " ")
+				hasRules = append(hasRules, false)
+			default:
+				b.WriteString(prelude)
+				hasRules = append(hasRules, false)
+			}
+			b.WriteByte('{')
+			start = i + 1
+		case '}', ';':
+			b.WriteString(s[start : i+1])
+			if s[i] == '}' && len(hasRules) > 0 {
+				hasRules = hasRules[:len(hasRules)-1]
+			}
+			start = i + 1
+		}
+	}
+	b.WriteString(s[start:])
+	return b.String()
+}
+
+//scopeSelectors prefixes each selector in a comma-separated list with scope
+func scopeSelectors(selectors, scope string) string {
+	list := strings.Split(selectors, ",")
+	for i, selector := range list {
+		selector = strings.TrimSpace(selector)
+		switch {
+		case selector == "html" || selector == "body":
+			list[i] = scope
+		case strings.HasPrefix(selector, "html ") || strings.HasPrefix(selector, "body "):
+			list[i] = scope + selector[4:]
+		default:
+			list[i] = scope + " " + selector
+		}
+	}
+	return strings.Join(list, ", ")
+}
+
+var (
+	cssComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	cssClass   = regexp.MustCompile(`\.(-?[_a-zA-Z][_a-zA-Z0-9-]*)`)
+)
+
 func (hr *htmlRenderer) Err() error {
 	return hr.htmlError
 }
 
+//Clone implements table.Cloner; the copy shares the loaded css
+func (hr *htmlRenderer) Clone() (table.Renderer, error) {
+	c := *hr
+	c.bw, c.htmlError = nil, nil
+	return &c, nil
+}
+
 // write does all the writing to the writer and handles errors by stopping any further writing
 // and returning the error
 func (hr *htmlRenderer) write(s string) error { //TODO:optimize
@@ -114,94 +264,208 @@ func (hr *htmlRenderer) write(s string) error { //TODO:optimize
 }
 
 func (hr *htmlRenderer) StartFile() error {
-	var b strings.Builder //optimization for golang >= 1.10
-	b.Grow(1024 * 100)    //preallocate 100kb to avoid additional allocations
-	b.WriteString(htmlHeader)
-	b.WriteString(`<meta name="date-generated" content="`)
-	b.WriteString(time.Now().Format("2006-01-02 15:04:05"))
-	b.WriteString(`" scheme="YYYY-MM-DD HH:MM:SS">` + "\n")
-	// FIXME: add settings.HeaderText to support writing anything by the caller to the header
-	// ExecutableVersion := fmt.Sprintf("Exe Version %s, Lib Version %s", hr.settings.ExecutableVersion, hr.settings.LibVersion)
-	if hr.settings.DoNotInlineCSS {
-		b.WriteString(`<link rel="stylesheet" type="text/css" href="` + string(hr.css) + `">`)
-	} else {
-		b.WriteString("<style>\n")
-		b.Write(hr.css)
-		b.WriteString("\n</style>\n")
+	hr.tableNum = 0
+	hr.idPrefix = "rw-"
+	if hr.settings.HTMLFragment {
+		if id := hr.fragmentID(); id != "" {
+			hr.idPrefix = "rw-" + id + "-"
+		}
+		return hr.startFragment()
 	}
-	b.WriteString(htmlBody)
-	if hr.settings.Debug >= ui.DebugAll {
-		b.WriteString(time.Now().Format("2006-01-02 15:04:05"))
+	return hr.execute("page-start", hr.pageData())
+}
+
+//pageData returns the data passed to the page templates
+func (hr *htmlRenderer) pageData() *PageData {
+	data := &PageData{
+		Generated:     time.Now().Format("2006-01-02 15:04:05"),
+		CSSHref:       hr.cssHref,
+		Debug:         hr.settings.Debug >= ui.DebugAll,
+		Tables:        hr.tables,
+		InputFileName: hr.fileName,
+		Settings:      hr.settings,
+		Job:           hr.job,
 	}
-	hr.write(b.String())
-	return hr.Err()
+	if hr.cssHref == "" {
+		data.CSS = template.CSS(hr.css) //the stylesheet is trusted
+	}
+	return data
 }
 
 func (hr *htmlRenderer) EndFile() error {
-	return hr.write(htmlFooter)
+	switch {
+	case !hr.settings.HTMLFragment:
+		return hr.execute("page-end", hr.pageData())
+	case hr.settings.ScopedCSS:
+		return hr.write("</div>\n")
+	}
+	return nil
+}
+
+//startFragment starts an html fragment, which holds only the tables so that it can be embedded in other pages.
+//With ScopedCSS, the fragment is wrapped in a div that holds the stylesheet with its rules limited to the div.
+//The div also has a class named after the fragment id so that the rules of one fragment do not apply to others
+//on the same page
+func (hr *htmlRenderer) startFragment() error {
+	if !hr.settings.ScopedCSS {
+		return nil
+	}
+	class, scope := fragmentClass, fragmentClass
+	if id := hr.fragmentID(); id != "" {
+		scope += "-" + id
+		class += " " + scope
+	}
+	var b strings.Builder
+	b.WriteString(`<div class="` + class + `">` + "\n<style>\n")
+	b.WriteString(scopeCSS(hr.css, "."+scope))
+	b.WriteString("\n</style>\n")
+	return hr.write(b.String())
+}
+
+//fragmentID returns the FragmentID setting or, if empty, the name of the input file without its directory and
+//extension, with the characters that are not allowed in css class names replaced by -
+func (hr *htmlRenderer) fragmentID() string {
+	id := strings.TrimSpace(hr.settings.FragmentID)
+	if id == "" && hr.fileName != "" {
+		id = filepath.Base(hr.fileName)
+		id = strings.TrimSuffix(id, filepath.Ext(id))
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return '-'
+	}, id)
 }
 
 func (hr *htmlRenderer) StartTable(t *table.Table) error {
-	hr.write(`<table class="rw-table">`)
+	if i := table.IndexOf(hr.tables, t); i >= 0 { //position in the file so that ids do not depend on rendering order
+		hr.tableNum = i + 1
+	} else {
+		hr.tableNum++
+	}
+	hr.section = ""
+	hr.complex = t.ComplexHeaders()
+	hr.current = t
+	data := hr.tableData(t)
+	hr.execute("table-start", data)
 	if t.Caption != nil {
-		hr.write("<caption>")
-		for _, line := range t.Caption.Lines {
-			hr.write(hr.renderText(line))
-		}
-		hr.write("</caption>\n") //added for completeness
+		hr.execute("caption", data)
 	}
 	return hr.Err()
 }
 
 func (hr *htmlRenderer) EndTable(t *table.Table) error {
-	hr.write("</table>\n")
+	data := hr.tableData(t)
+	data.Section = hr.section
+	hr.execute("table-end", data)
+	if t.Footnotes != nil {
+		hr.execute("footnotes", data)
+	}
+	return hr.Err()
+}
+
+//tableData returns the data passed to the table templates
+func (hr *htmlRenderer) tableData(t *table.Table) *TableData {
+	data := &TableData{Table: t, Number: hr.tableNum}
+	if t.Caption != nil {
+		for _, line := range t.Caption.Lines {
+			data.Caption = append(data.Caption, template.HTML(hr.renderText(line)))
+		}
+	}
 	if t.Footnotes != nil {
-		hr.write(`<div class="rw-footnotes">` + "\n")
 		for _, line := range t.Footnotes.Lines {
-			hr.write(hr.renderText(line) + "<br>\n")
+			data.Footnotes = append(data.Footnotes, template.HTML(hr.renderText(line)))
 		}
-		hr.write("</div>\n")
 	}
-	return hr.Err()
+	return data
 }
 
 func (hr *htmlRenderer) StartRow(r *table.Row) error {
-	return hr.write(`<tr class="rw-row">` + "\n")
+	//rows are grouped into thead and tbody only if the table has header rows
+	section := "tbody"
+	if r.Head() {
+		section = "thead"
+	}
+	data := &RowData{Row: r, Table: hr.current}
+	if (hr.section != "" || r.Head()) && section != hr.section {
+		data.Close, data.Open = hr.section, section
+		hr.section = section
+	}
+	return hr.execute("row-start", data)
 }
 
 func (hr *htmlRenderer) EndRow(r *table.Row) error {
-	return hr.write("</tr>\n")
+	return hr.execute("row-end", &RowData{Row: r, Table: hr.current})
 }
 
 func (hr *htmlRenderer) OutputCell(c *table.Cell) error {
 	if c.Merged() { //skip merged cells
 		return nil
 	}
-	tag := "td"
+	data := &CellData{Cell: c, Table: hr.current}
+	styles := c.Styles()
+	if c.Indent() > 0 { //eg rw-indent-2 for the second indent level
+		styles = append(styles, "rw-indent-"+strconv.Itoa(c.Indent()))
+	}
+	data.Class = strings.Join(styles, " ")
 	if c.Header() {
-		tag = "th"
+		data.Scope = headerScope(c)
+		if hr.complex {
+			data.ID = hr.cellID(c)
+		}
+	}
+	if hr.complex && len(c.HeaderCells()) > 0 { //eg headers="rw-t1-r1c2 rw-t1-r2c2"
+		ids := make([]string, len(c.HeaderCells()))
+		for i, h := range c.HeaderCells() {
+			ids[i] = hr.cellID(h)
+		}
+		data.Headers = strings.Join(ids, " ")
 	}
 	var b strings.Builder //optimization for golang >= 1.10
-	b.Grow(1024)
-	b.WriteString("  <" + tag) //open td or th tag
-	// write styles
-	switch len(c.Styles()) {
-	case 0: //donothing
-	case 1: //optimization for the common scenario with only one style
-		b.WriteString(` class="` + c.Styles()[0] + `"`) //eg class="style1"
-	default:
-		b.WriteString(` class="` + strings.Join(c.Styles(), " ") + `"`)
+	if before, after, bw, aw, ok := c.Aligned(); ok {
+		//the part before the alignment point is right-aligned and the part after it left-aligned in boxes as
+		//wide as the widest parts in the column, which lines up the alignment points
+		fmt.Fprintf(&b, alignedSpan, "before", bw, hr.renderText(before))
+		fmt.Fprintf(&b, alignedSpan, "after", aw, hr.renderText(after))
+	} else {
+		// trim cell contents b/c html ignores white space anyway
+		b.WriteString(hr.renderText(strings.TrimSpace(c.Text())))
 	}
-	if c.RowSpan() > 1 {
-		b.WriteString(fmt.Sprintf(" rowspan=\"%d\"", c.RowSpan())) //eg rowspan="3"
+	data.Content = template.HTML(b.String()) //rendered from the markdown of the source file
+	return hr.execute("cell", data)
+}
+
+//execute writes the output of the named layout template
+func (hr *htmlRenderer) execute(name string, data interface{}) error {
+	if hr.htmlError != nil {
+		return hr.htmlError
 	}
-	if c.ColSpan() > 1 {
-		b.WriteString(fmt.Sprintf(" colspan=\"%d\"", c.ColSpan())) // eg colspan="2"
+	var b strings.Builder
+	if err := hr.layout.ExecuteTemplate(&b, name, data); err != nil {
+		hr.htmlError = fmt.Errorf("failed to execute html template %s: %s", name, err)
+		return hr.htmlError
+	}
+	return hr.write(b.String())
+}
+
+//cellID returns an id for a header cell that is unique within the html file; ids of fragments include the
+//fragment id so that they are also unique within the page that embeds them
+func (hr *htmlRenderer) cellID(c *table.Cell) string {
+	return fmt.Sprintf("%st%d-r%dc%d", hr.idPrefix, hr.tableNum, c.Row(), c.Col())
+}
+
+//headerScope returns the scope attribute of a header cell; header cells spanning several
+//columns (rows) are headers for a colgroup (rowgroup)
+func headerScope(c *table.Cell) string {
+	switch {
+	case c.Scope() == "col" && c.ColSpan() > 1:
+		return "colgroup"
+	case c.Scope() == "row" && c.RowSpan() > 1:
+		return "rowgroup"
+	default:
+		return c.Scope()
 	}
-	// trim cell contents b/c html ignores white space anyway
-	b.WriteString(">" + hr.renderText(strings.TrimSpace(c.Text())) + "</" + tag + ">\n") //eg "> text </td>"
-	hr.write(b.String())
-	return hr.Err()
 }
 
 func (hr *htmlRenderer) renderText(s string) string {
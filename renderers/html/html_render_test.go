@@ -2,6 +2,417 @@
 
 package html
 
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/drgo/rosewood"
+	"github.com/drgo/rosewood/table"
+)
+
+const headerTab = `+++
+Table 1. Header rows
++++
+Brand|Some numbers||
+|Models|Price|
+AMC|3|4,215.67|
++++
++++
+merge row 1:2 col 1
+merge row 1 col 2:3
+header row 1:2
+header col 1
++++
+`
+
+const simpleHeaderTab = `+++
+Table 2. One header row
++++
+Brand|Models|
+AMC|3|
++++
++++
+header row 1
++++
+`
+
+const alignedTab = `+++
+Table 3. Aligned cols
++++
+Outcome|OR (95% CI)|
+Stroke|1.20 (0.90-1.50)|
+MI|10.5 (2.1-52.3)|
+Death|NA|
++++
++++
+header row 1
+align col 2 on "("
++++
+`
+
+func TestRenderHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		want     []string
+		dontWant []string
+	}{
+		{"complex headers", headerTab, []string{
+			"<thead>\n<tr",
+			"</thead>\n<tbody>\n<tr",
+			"</tbody>\n</table>",
+			`<th rowspan="2" scope="col" id="rw-t1-r1c1">Brand</th>`,
+			`<th colspan="2" scope="colgroup" id="rw-t1-r1c2">Some numbers</th>`,
+			`<th scope="col" id="rw-t1-r2c3" headers="rw-t1-r1c2">Price</th>`,
+			`<th scope="row" id="rw-t1-r3c1" headers="rw-t1-r1c1">AMC</th>`,
+			`<td headers="rw-t1-r1c2 rw-t1-r2c3 rw-t1-r3c1">4,215.67</td>`,
+		}, nil},
+		{"simple headers", simpleHeaderTab, []string{
+			`<th scope="col">Brand</th>`,
+			"<td>3</td>",
+		}, []string{"id=", "headers="}},
+		{"aligned cells", alignedTab, []string{
+			`<td><span class="rw-align-before" style="min-width:5ch">1.20 </span>` +
+				`<span class="rw-align-after" style="min-width:11ch">(0.90-1.50)</span></td>`,
+			`style="min-width:5ch">NA</span>`,
+			".rw-align-before { text-align: right; }", //from the theme
+			`<th scope="col">OR (95% CI)</th>`,
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := rosewood.DefaultJob(rosewood.DefaultSettings())
+			ri := rosewood.NewInterpreter(job)
+			file, err := ri.Parse(strings.NewReader(tt.src), tt.name)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			hr, _ := NewHTMLRenderer()
+			w := &bytes.Buffer{}
+			if err := ri.Render(w, file, hr); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(w.String(), want) {
+					t.Errorf("wanted string [%s] was not found in\n%s", want, w.String())
+				}
+			}
+			for _, dontWant := range tt.dontWant {
+				if strings.Contains(w.String(), dontWant) {
+					t.Errorf("unwanted string [%s] was found", dontWant)
+				}
+			}
+		})
+	}
+}
+
+func TestCSSClasses(t *testing.T) {
+	tests := []struct {
+		css  string
+		want []string
+	}{
+		{"", []string{}},
+		{"td.total, .rw-indent-1 > span {padding-left: 1em}", []string{"rw-indent-1", "total"}},
+		{"/* .old {} */ th { background: url(img.png); margin: .5em }", []string{}},
+		{"@media print { .screen-only { display: none } }\n.bold{font-weight:bold}", []string{"bold", "screen-only"}},
+		{"@import url(base.css);\n._private:hover{}", []string{"_private"}},
+	}
+	for _, tt := range tests {
+		got := []string{}
+		for class := range cssClasses([]byte(tt.css)) {
+			got = append(got, class)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("cssClasses(%q) = %v, want %v", tt.css, got, tt.want)
+		}
+	}
+}
+
+func TestScopeCSS(t *testing.T) {
+	tests := []struct {
+		css, want string
+	}{
+		{"td.total, th { color: red; }", ".s td.total, .s th { color: red; }"},
+		{"html { font-size: 10pt }\nbody p {}", ".s { font-size: 10pt }\n.s p {}"},
+		{"/* note */ @media print { .screen { display: none } }", " @media print { .s .screen { display: none } }"},
+		{"@import url(a.css);\n@font-face { font-family: x; }", "@import url(a.css);\n@font-face { font-family: x; }"},
+		{"@keyframes fade { from { opacity: 0 } }", "@keyframes fade { from { opacity: 0 } }"},
+	}
+	for _, tt := range tests {
+		if got := scopeCSS([]byte(tt.css), ".s"); got != tt.want {
+			t.Errorf("scopeCSS(%q) = %q, want %q", tt.css, got, tt.want)
+		}
+	}
+}
+
+func TestRenderFragment(t *testing.T) {
+	tests := []struct {
+		name             string
+		src, fileName    string
+		fragmentID       string
+		scoped, noInline bool
+		wantPrefix       string
+		want             []string
+		wantErr          string
+	}{
+		{"fragment", simpleHeaderTab, "tables.rw", "", false, false, `<table class="rw-table">`, []string{"</table>\n"}, ""},
+		{"scoped css", simpleHeaderTab, "tables.rw", "", true, false,
+			`<div class="rw-fragment rw-fragment-tables">` + "\n<style>\n", []string{
+				".rw-fragment-tables .bold { font-weight: bold; }",
+				".rw-fragment-tables {\n    font-family: 'Bell MT'",
+				"</style>\n<table class=\"rw-table\">",
+				"<div class=\"rw-footnotes\">\n</div>\n</div>\n",
+			}, ""},
+		{"no file name", simpleHeaderTab, "", "", true, false, `<div class="rw-fragment">`, []string{
+			".rw-fragment .bold { font-weight: bold; }",
+		}, ""},
+		{"scope from file path", simpleHeaderTab, filepath.Join("reports", "table 2.v1.rw"), "", true, false,
+			`<div class="rw-fragment rw-fragment-table-2-v1">`, []string{".rw-fragment-table-2-v1 .bold {"}, ""},
+		{"header ids", headerTab, "tables.rw", "", false, false, `<table class="rw-table">`, []string{
+			`<th rowspan="2" scope="col" id="rw-tables-t1-r1c1">Brand</th>`,
+			`<td headers="rw-tables-t1-r1c2 rw-tables-t1-r2c3 rw-tables-t1-r3c1">4,215.67</td>`,
+		}, ""},
+		{"fragment id setting", headerTab, "tables.rw", "results", true, false,
+			`<div class="rw-fragment rw-fragment-results">`, []string{
+				`<th rowspan="2" scope="col" id="rw-results-t1-r1c1">Brand</th>`,
+			}, ""},
+		{"scoped linked css", simpleHeaderTab, "tables.rw", "", true, true, "", nil, "cannot scope the linked css file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := rosewood.DefaultSettings()
+			settings.HTMLFragment, settings.ScopedCSS, settings.FragmentID = true, tt.scoped, tt.fragmentID
+			if tt.noInline {
+				settings.DoNotInlineCSS, settings.StyleSheetName = true, "styles.css"
+			}
+			ri := rosewood.NewInterpreter(rosewood.DefaultJob(settings))
+			file, err := ri.Parse(strings.NewReader(tt.src), tt.fileName)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			hr, _ := NewHTMLRenderer()
+			w := &bytes.Buffer{}
+			err = ri.Render(w, file, hr)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Render() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got := w.String(); !strings.HasPrefix(got, tt.wantPrefix) || strings.Contains(got, "<head>") {
+				t.Errorf("Render() = %s, want an html fragment starting with %s", got, tt.wantPrefix)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(w.String(), want) {
+					t.Errorf("wanted string [%s] was not found in\n%s", want, w.String())
+				}
+			}
+		})
+	}
+}
+
+const deptLayout = `{{define "page-start"}}<html><body><h1>{{.Job.RunOptions.Command}} {{.InputFileName}}</h1>
+{{- range .Tables}}{{range .Caption.Lines}}<h2>{{.}}</h2>{{end}}{{end}}
+{{end}}
+{{define "cell"}}<td data-row="{{.Cell.Row}}" data-styles="{{range .Cell.Styles}}{{.}};{{end}}">{{.Content}}</td>
+{{end}}`
+
+const tableLayout = `{{define "table-start"}}<table class="data" data-table="{{.Number}}">{{end}}
+{{define "caption"}}<caption>{{range .Caption}}<b>{{.}}</b>{{end}}</caption>{{end}}
+{{define "row-start"}}{{if .Row.Head}}<tr class="head">{{else}}<tr>{{end}}{{end}}
+{{define "row-end"}}</tr>{{end}}
+{{define "table-end"}}</table>{{end}}
+{{define "footnotes"}}<footer>{{range .Footnotes}}<p>{{.}}</p>{{end}}</footer>{{end}}`
+
+func TestRenderTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rosewood")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, src := range map[string]string{
+		"dept.tmpl":  deptLayout,
+		"table.tmpl": tableLayout,
+		"bad.tmpl":   `{{define "cell"}}{{.Missing}}{{end}}`,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name     string
+		template string
+		want     []string
+		wantErr  string
+	}{
+		{"department layout", filepath.Join(dir, "dept.tmpl"), []string{
+			"<html><body><h1>run simple.rw</h1><h2>Table 2. One header row</h2>\n",
+			`<td data-row="2" data-styles="">AMC</td>`,
+			"</body>\n</html>\n", //default page-end
+		}, ""},
+		{"table layout", filepath.Join(dir, "table.tmpl"), []string{
+			`<table class="data" data-table="1"><caption><b>Table 2. One header row</b></caption><tr class="head">  <th`,
+			"</th>\n</tr><tr>  <td>AMC</td>\n",
+			"</tr></table><footer></footer>",
+		}, ""},
+		{"missing template", filepath.Join(dir, "none.tmpl"), nil, "failed to load html template"},
+		{"invalid template", filepath.Join(dir, "bad.tmpl"), nil, "failed to execute html template cell"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := rosewood.DefaultSettings()
+			settings.HTMLTemplateName = tt.template
+			ri := rosewood.NewInterpreter(rosewood.DefaultJob(settings))
+			file, err := ri.Parse(strings.NewReader(simpleHeaderTab), "simple.rw")
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			hr, _ := NewHTMLRenderer()
+			w := &bytes.Buffer{}
+			err = ri.Render(w, file, hr)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Render() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(w.String(), want) {
+					t.Errorf("wanted string [%s] was not found in\n%s", want, w.String())
+				}
+			}
+		})
+	}
+}
+
+func TestLoadTheme(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rosewood")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "journal.css"), []byte(".mine {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	searchPath := filepath.Join(dir, "missing") + string(os.PathListSeparator) + dir
+	tests := []struct {
+		name, searchPath string
+		want             string //substring of the css
+		wantFile         string
+		wantErr          string
+	}{
+		{"", "", "font-family: 'Bell MT'", "", ""},
+		{"compact", searchPath, "font-size: 10pt", "", ""},
+		{"journal", "", ".rw-indent-1 { padding-left: 1.5em; }", "", ""},
+		{"journal", searchPath, ".mine {}", filepath.Join(dir, "journal.css"), ""},
+		{"journal", searchPath, ".rw-indent-1 {", filepath.Join(dir, "journal.css"), ""}, //user themes get the base rules
+		{"fancy", searchPath, "", "", "unknown theme fancy, expected one of compact, default, journal, minimal, print"},
+	}
+	for _, tt := range tests {
+		css, fileName, err := loadTheme(tt.name, tt.searchPath)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadTheme(%s) error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !strings.Contains(string(css), tt.want) || fileName != tt.wantFile {
+			t.Errorf("loadTheme(%s, %s) = %q, %s, %v, want css containing %q from %q", tt.name, tt.searchPath, css,
+				fileName, err, tt.want, tt.wantFile)
+		}
+	}
+}
+
+func TestDefaultThemeClasses(t *testing.T) {
+	old, err := ioutil.ReadFile(filepath.Join("..", "..", "test-files", "carpenter.css"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	css, _, err := loadTheme(defaultTheme, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	classes := cssClasses(css)
+	for class := range cssClasses(old) {
+		if !classes[class] {
+			t.Errorf("default theme lacks class %s of carpenter.css", class)
+		}
+	}
+	if !strings.Contains(string(css), "table-layout:fixed;") {
+		t.Errorf("default theme lacks the table layout of carpenter.css")
+	}
+}
+
+const styledTab = `+++
+Table 1
++++
+a|b|
+c|d|
++++
++++
+style row 1 header
+style row 2 bold heder
+// comment
+style col 2 bold italic
++++
+`
+
+func TestStyleWarnings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rosewood")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cssFileName := filepath.Join(dir, "styles.css")
+	if err := ioutil.WriteFile(cssFileName, []byte(".bold {font-weight: bold}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		css      string
+		noInline bool
+		want     []string
+	}{
+		{"stylesheet", cssFileName, false, []string{
+			"line 9: style heder is not defined in " + cssFileName,
+			"line 11: style italic is not defined in " + cssFileName,
+		}},
+		{"linked stylesheet", cssFileName, true, nil},
+		{"default theme", "", false, []string{"line 9: style heder is not defined in theme default"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := rosewood.DefaultSettings()
+			settings.StyleSheetName, settings.DoNotInlineCSS = tt.css, tt.noInline
+			ri := rosewood.NewInterpreter(rosewood.DefaultJob(settings))
+			file, err := ri.Parse(strings.NewReader(styledTab), tt.name)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			hr, _ := NewHTMLRenderer()
+			if err := ri.Render(&bytes.Buffer{}, file, hr); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got := hr.(table.Warner).Warnings(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Warnings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 // import (
 // 	"bytes"
 // 	"testing"
@@ -0,0 +1,139 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package html
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/drgo/rosewood/table"
+	"github.com/drgo/rosewood/types"
+)
+
+//defaultLayout holds the templates used to write html pages, tables, rows and cells. A template file named in
+//HTMLTemplateName may redefine any of them:
+//
+//	page-start   everything before the first table; executed with a PageData
+//	page-end     everything after the last table; executed with a PageData
+//	table-start  the opening table tag; executed with a TableData
+//	caption      the table caption, if the table has one; executed with a TableData
+//	row-start    the opening tr tag and any thead or tbody tags before it; executed with a RowData
+//	cell         a td or th element; executed with a CellData
+//	row-end      the closing tr tag; executed with a RowData
+//	table-end    the closing table tag and any open thead or tbody; executed with a TableData
+//	footnotes    the table footnotes, if the table has any; executed with a TableData
+//
+//Page templates are not used for html fragments
+const defaultLayout = `{{define "page-start"}}<!DOCTYPE html>
+<head>
+<meta charset="utf-8">
+<meta name="generator" content="Rosewood Carpenter" />
+<meta name="date-generated" content="{{.Generated}}" scheme="YYYY-MM-DD HH:MM:SS">
+{{if .CSSHref}}<link rel="stylesheet" type="text/css" href="{{.CSSHref}}">
+{{else}}<style>
+{{.CSS}}
+</style>
+{{end}}</head>
+<body>
+{{if .Debug}}{{.Generated}}
+{{end}}{{end}}
+
+{{define "page-end"}}
+</body>
+</html>
+{{end}}
+
+{{define "table-start"}}<table class="rw-table">{{end}}
+
+{{define "caption"}}<caption>{{range .Caption}}{{.}}{{end}}</caption>
+{{end}}
+
+{{define "row-start"}}
+{{- if eq .Close "thead"}}</thead>
+{{else if eq .Close "tbody"}}</tbody>
+{{end}}
+{{- if eq .Open "thead"}}<thead>
+{{else if eq .Open "tbody"}}<tbody>
+{{end -}}
+<tr class="rw-row">
+{{end}}
+
+{{define "row-end"}}</tr>
+{{end}}
+
+{{define "table-end"}}
+{{- if eq .Section "thead"}}</thead>
+{{else if eq .Section "tbody"}}</tbody>
+{{end -}}
+</table>
+{{end}}
+
+{{define "footnotes"}}<div class="rw-footnotes">
+{{range .Footnotes}}{{.}}<br>
+{{end}}</div>
+{{end}}
+
+{{define "cell"}}  {{if .Cell.Header}}<th{{else}}<td{{end -}}
+{{with .Class}} class="{{.}}"{{end}}
+{{- if gt .Cell.RowSpan 1}} rowspan="{{.Cell.RowSpan}}"{{end}}
+{{- if gt .Cell.ColSpan 1}} colspan="{{.Cell.ColSpan}}"{{end}}
+{{- with .Scope}} scope="{{.}}"{{end}}
+{{- with .ID}} id="{{.}}"{{end}}
+{{- with .Headers}} headers="{{.}}"{{end}}>{{.Content}}{{if .Cell.Header}}</th>{{else}}</td>{{end}}
+{{end}}`
+
+//PageData is the data passed to the page-start and page-end templates
+type PageData struct {
+	Generated     string       //date and time the page was generated, eg 2019-08-17 18:05:51
+	CSS           template.CSS //inlined stylesheet
+	CSSHref       string       //url of the linked stylesheet, if any; CSS is empty if set
+	Debug         bool         //true if the debug level is set to show all debug info
+	Tables        []*table.Table
+	InputFileName string                  //name of the rendered Rosewood file
+	Settings      *types.RosewoodSettings //eg {{.Settings.Theme}}
+	Job           *types.Job              //job metadata, eg {{.Job.RunOptions.ConfigFileName}}; nil if unknown
+}
+
+//TableData is the data passed to the table-start, caption, table-end and footnotes templates
+type TableData struct {
+	Table     *table.Table    //eg {{.Table.ComplexHeaders}}
+	Number    int             //position of the table in the file, starting at 1
+	Caption   []template.HTML //caption lines rendered as html
+	Footnotes []template.HTML //footnote lines rendered as html
+	Section   string          //row group left open by the last row: thead, tbody or "" if none
+}
+
+//RowData is the data passed to the row-start and row-end templates
+type RowData struct {
+	Row   *table.Row   //eg {{.Row.Head}}
+	Table *table.Table //table that holds the row
+	Close string       //row group closed before the row: thead, tbody or "" if none
+	Open  string       //row group opened before the row: thead, tbody or "" if none
+}
+
+//CellData is the data passed to the cell template
+type CellData struct {
+	Cell    *table.Cell   //eg {{.Cell.Text}}, {{.Cell.Styles}} or {{.Cell.Row}}
+	Table   *table.Table  //table that holds the cell, eg {{.Table.Caption.Lines}}
+	Class   string        //space-separated style names of the cell, including its indent level, eg rw-indent-1
+	Scope   string        //scope attribute of a header cell, if needed
+	ID      string        //id of a header cell in a table with complex headers
+	Headers string        //ids of the header cells of a cell in a table with complex headers
+	Content template.HTML //cell contents rendered as html
+}
+
+//loadLayout returns the default templates updated from the template file fileName, if not empty
+func loadLayout(fileName string) (*template.Template, error) {
+	layout, err := template.New("layout").Parse(defaultLayout)
+	if err != nil {
+		panic(err) //should never happen
+	}
+	if fileName = strings.TrimSpace(fileName); fileName == "" {
+		return layout, nil
+	}
+	if layout, err = layout.ParseFiles(fileName); err != nil {
+		return nil, fmt.Errorf("failed to load html template %s: %s", fileName, err)
+	}
+	return layout, nil
+}
@@ -0,0 +1,196 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package html
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//defaultTheme is used if neither a stylesheet nor a theme is specified in the settings
+const defaultTheme = "default"
+
+//themeBase holds the rules shared by all themes, built-in or user: the style names known to all renderers (see
+//types.DefaultStyleRegistry) and the classes written by the renderer for indent levels and aligned cells
+const themeBase = `
+.bold { font-weight: bold; }
+.italic { font-style: italic; }
+.left { text-align: left; }
+.center { text-align: center; }
+.right { text-align: right; }
+.indent { padding-left: 1.5em; }
+.border-top { border-top: solid 1px black; }
+.border-bottom { border-bottom: solid 1px black; }
+.border-left { border-left: solid 1px black; }
+.border-right { border-right: solid 1px black; }
+.shaded { background-color: #D9D9D9; }
+.rw-indent-1 { padding-left: 1.5em; }
+.rw-indent-2 { padding-left: 3em; }
+.rw-indent-3 { padding-left: 4.5em; }
+.rw-indent-4 { padding-left: 6em; }
+.rw-indent-5 { padding-left: 7.5em; }
+.rw-indent-6 { padding-left: 9em; }
+.rw-align-before, .rw-align-after { display: inline-block; white-space: pre; font-variant-numeric: tabular-nums; }
+.rw-align-before { text-align: right; }
+.rw-align-after { text-align: left; }
+.rw-footnotes { margin-top: 0.5em; }
+`
+
+//themes holds the css of the built-in themes by name; it follows themeBase in the stylesheet and so may
+//override its rules
+var themes = map[string]string{
+	//the carpenter.css file that was previously distributed with carpenter, verbatim
+	"default": `
+html {
+    font-family: 'Bell MT', helvetica, arial, sans-serif;
+}
+body {
+    color: black;
+    background-color: rgb(232, 230, 223) ;
+}
+
+table {
+    table-layout:fixed;
+    width: fixed;
+    border-collapse: collapse;
+    border: none ;
+    /* background-color: #D5DCE6; */
+  }
+
+  th {
+    letter-spacing: 2px;
+  }
+  
+  td {
+    letter-spacing: 1px;
+  }
+  
+ /* tbody tr {
+      border: solid thin;  
+ }   */
+  tbody td {
+    text-align: center;
+  }
+  
+  table tr td:first-child {
+     text-align: left;
+  }
+
+  /* tbody tr:nth-child(odd) {
+    background-color: #ff33cc;
+  }
+  
+  tbody tr:nth-child(even) {
+    background-color: #e495e4;
+  } */
+
+  caption {
+    /* font-family: 'Rock Salt', cursive; */
+    padding: 10px;
+    font-style: italic;    
+    font-weight: bold;    
+    caption-side: top;
+    color: #666;
+    text-align: left;
+    letter-spacing: 1px;
+  }
+
+  tr {
+    border-bottom: solid 1px black;
+    border-top: solid 1px black;    
+  }
+
+  .header {
+    font-weight: bold;   
+  }
+
+  .red {
+    color: red;   
+  }
+
+  .rw-cell {
+    text-align: center;
+    border-bottom: solid 1px black;
+    border-top: solid 1px black;       
+  }
+`,
+	//three horizontal rules and no shading, as required by most medical and scientific journals
+	"journal": `
+body { font-family: 'Times New Roman', Times, serif; color: black; background-color: white; }
+table { border-collapse: collapse; border-top: solid 2px black; border-bottom: solid 2px black; }
+caption { caption-side: top; text-align: left; font-weight: bold; padding: 0.5em 0; }
+th, td { padding: 0.2em 0.75em; vertical-align: top; }
+thead { border-bottom: solid 1px black; }
+th { font-weight: bold; text-align: center; }
+tbody td { text-align: center; }
+table tr td:first-child, table tr th:first-child { text-align: left; }
+.rw-footnotes { font-size: 0.9em; }
+`,
+	//no borders, a light rule under the headings and the browser's default fonts
+	"minimal": `
+table { border-collapse: collapse; }
+caption { caption-side: top; text-align: left; padding: 0.5em 0; }
+th, td { padding: 0.25em 0.75em; }
+thead th { border-bottom: solid 1px #999; }
+`,
+	//black on white with all cell borders and no page breaks inside rows
+	"print": `
+body { font-family: Georgia, 'Times New Roman', serif; font-size: 11pt; color: black; background-color: white; }
+table { border-collapse: collapse; page-break-inside: auto; }
+tr { page-break-inside: avoid; }
+thead { display: table-header-group; }
+caption { caption-side: top; text-align: left; font-weight: bold; padding: 0.5em 0; }
+th, td { border: solid 1px black; padding: 0.2em 0.5em; }
+.shaded { background-color: #D9D9D9; -webkit-print-color-adjust: exact; print-color-adjust: exact; }
+`,
+	//small type and tight padding for wide tables
+	"compact": `
+body { font-family: Arial, Helvetica, sans-serif; font-size: 10pt; }
+table { border-collapse: collapse; border-top: solid 1px black; border-bottom: solid 1px black; }
+caption { caption-side: top; text-align: left; font-weight: bold; padding: 0.25em 0; }
+th, td { padding: 0.1em 0.4em; }
+thead { border-bottom: solid 1px black; }
+tbody tr:nth-child(even) { background-color: #F2F2F2; }
+`,
+}
+
+//ThemeNames returns the sorted names of the built-in themes
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//loadTheme returns the css of the named theme (default if empty) and the name of the file it was read from,
+//if any. The file name.css is looked for in each directory of searchPath, which is a list separated by
+//os.PathListSeparator, before the built-in themes so that users can add or replace themes. User themes follow
+//themeBase as the built-in ones do, so they need only define the rules they change
+func loadTheme(name, searchPath string) (css []byte, fileName string, err error) {
+	if name == "" {
+		name = defaultTheme
+	}
+	for _, dir := range filepath.SplitList(searchPath) {
+		if dir = strings.TrimSpace(dir); dir == "" {
+			continue
+		}
+		fileName = filepath.Join(dir, name+".css")
+		if css, err = ioutil.ReadFile(fileName); err == nil {
+			return append([]byte(themeBase), css...), fileName, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, "", fmt.Errorf("failed to load theme %s: %s", name, err)
+		}
+	}
+	if s, ok := themes[name]; ok {
+		return []byte(themeBase + s), "", nil
+	}
+	return nil, "", fmt.Errorf("unknown theme %s, expected one of %s or a %s.css file in the theme path", name,
+		strings.Join(ThemeNames(), ", "), name)
+}
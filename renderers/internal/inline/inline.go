@@ -0,0 +1,158 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+//Package inline splits cell text containing inline markdown into runs of uniformly formatted text
+//for use by renderers that cannot consume HTML
+package inline
+
+import "strings"
+
+//Run holds a piece of text that shares the same inline formatting
+type Run struct {
+	Text        string
+	Bold        bool
+	Italic      bool
+	Code        bool
+	Strike      bool
+	Superscript bool
+	Subscript   bool
+}
+
+type markKind int
+
+const (
+	markNone markKind = iota
+	markBold
+	markItalic
+	markCode
+	markStrike
+	markSup
+	markSub
+)
+
+type token struct {
+	text    string
+	kind    markKind //markNone for text tokens
+	matched bool     //true if the delimiter has a matching opening/closing delimiter
+}
+
+//Parse splits s into runs; unmatched markdown delimiters are kept as literal text
+func Parse(s string) []Run {
+	tokens := tokenize(s)
+	matchDelimiters(tokens)
+	var (
+		runs []Run
+		cur  Run
+		b    strings.Builder
+	)
+	flush := func() {
+		if b.Len() > 0 {
+			cur.Text = b.String()
+			runs = append(runs, cur)
+			b.Reset()
+		}
+	}
+	for _, tok := range tokens {
+		if tok.kind == markNone || !tok.matched {
+			b.WriteString(tok.text)
+			continue
+		}
+		flush()
+		switch tok.kind { //matched delimiters toggle formatting
+		case markBold:
+			cur.Bold = !cur.Bold
+		case markItalic:
+			cur.Italic = !cur.Italic
+		case markCode:
+			cur.Code = !cur.Code
+		case markStrike:
+			cur.Strike = !cur.Strike
+		case markSup:
+			cur.Superscript = !cur.Superscript
+		case markSub:
+			cur.Subscript = !cur.Subscript
+		}
+	}
+	flush()
+	return runs
+}
+
+//PlainText returns s stripped of all matched markdown delimiters
+func PlainText(s string) string {
+	var b strings.Builder
+	for _, r := range Parse(s) {
+		b.WriteString(r.Text)
+	}
+	return b.String()
+}
+
+func tokenize(s string) []token {
+	var tokens []token
+	var b strings.Builder
+	emit := func(text string, kind markKind) {
+		if b.Len() > 0 {
+			tokens = append(tokens, token{text: b.String()})
+			b.Reset()
+		}
+		tokens = append(tokens, token{text: text, kind: kind})
+	}
+	inCode := false
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if inCode && ch != '`' { //no formatting inside code spans
+			b.WriteByte(ch)
+			continue
+		}
+		switch {
+		case ch == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_~^", s[i+1]) >= 0: //escaped delimiter
+			b.WriteByte(s[i+1])
+			i++
+		case ch == '`':
+			inCode = !inCode
+			emit("`", markCode)
+		case (ch == '*' || ch == '_') && i+1 < len(s) && s[i+1] == ch:
+			emit(s[i:i+2], markBold)
+			i++
+		case ch == '*':
+			emit("*", markItalic)
+		case ch == '_' && !isIntraword(s, i): //snake_case is not italics
+			emit("_", markItalic)
+		case ch == '~' && i+1 < len(s) && s[i+1] == '~':
+			emit("~~", markStrike)
+			i++
+		case ch == '~':
+			emit("~", markSub)
+		case ch == '^':
+			emit("^", markSup)
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	if b.Len() > 0 {
+		tokens = append(tokens, token{text: b.String()})
+	}
+	return tokens
+}
+
+//matchDelimiters pairs each opening delimiter with the next closing delimiter of the same kind and text
+func matchDelimiters(tokens []token) {
+	open := make(map[string]int) //delimiter text -> index of the currently open delimiter
+	for i := range tokens {
+		if tokens[i].kind == markNone {
+			continue
+		}
+		if j, ok := open[tokens[i].text]; ok {
+			tokens[i].matched = true
+			tokens[j].matched = true
+			delete(open, tokens[i].text)
+			continue
+		}
+		open[tokens[i].text] = i
+	}
+}
+
+func isIntraword(s string, i int) bool {
+	isAlnum := func(ch byte) bool {
+		return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9'
+	}
+	return i > 0 && i+1 < len(s) && isAlnum(s[i-1]) && isAlnum(s[i+1])
+}
@@ -0,0 +1,369 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+//Package latex implements a table.Renderer that writes Rosewood tables as LaTeX booktabs tables
+package latex
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/drgo/rosewood"
+	"github.com/drgo/rosewood/renderers/internal/inline"
+	"github.com/drgo/rosewood/table"
+	"github.com/drgo/rosewood/types"
+)
+
+const (
+	latexHeader = `%% Generated by Rosewood Carpenter on %s
+%% requires \usepackage{booktabs,multirow,longtable} and \usepackage[normalem]{ulem} in the preamble
+%% and \usepackage[table]{xcolor} for shaded cells
+`
+	//tables with more rows than this are written as a longtable which can break across pages
+	longTableRowCount = 40
+)
+
+//init, run automatically, registers LaTeX renderer with Rosewood
+func init() {
+	config := rosewood.RendererConfig{
+		Name:     "latex",
+		Renderer: makeLaTeXRenderer,
+	}
+	rosewood.RegisterRenderer(&config)
+}
+
+//latexRenderer implements table.Renderer for LaTeX output
+type latexRenderer struct {
+	bw         io.Writer
+	settings   *types.RosewoodSettings
+	styles     *types.StyleRegistry
+	tables     []*table.Table
+	latexError error         //tracks errors
+	long       bool          //current table is a longtable
+	inHeader   bool          //true while writing the header rows of the current table
+	rowCells   []*table.Cell //cells of the current row
+	caption    string        //caption of the current table
+	rowCount   int           //number of rows in the current table
+	rowNum     int           //number of the current row
+	headerRows int           //number of header rows at the top of the current table
+}
+
+//makeLaTeXRenderer factory function according to the renderer registration requirements
+func makeLaTeXRenderer() (table.Renderer, error) {
+	return NewLaTeXRenderer()
+}
+
+//NewLaTeXRenderer create a new latexRenderer and return it as a Renderer
+func NewLaTeXRenderer() (table.Renderer, error) {
+	return &latexRenderer{styles: types.DefaultStyleRegistry()}, nil
+}
+
+func (lr *latexRenderer) SetWriter(w io.Writer) error {
+	lr.bw = w
+	return nil
+}
+
+func (lr *latexRenderer) SetSettings(settings *types.RosewoodSettings) error {
+	lr.settings = settings
+	var err error
+	lr.styles, err = types.LoadStyleRegistry(settings.StylesFileName)
+	return err
+}
+
+func (lr *latexRenderer) SetTables(tables []*table.Table) error {
+	lr.tables = tables
+	return nil
+}
+
+func (lr *latexRenderer) Err() error {
+	return lr.latexError
+}
+
+//Clone returns a renderer with the same settings for concurrent rendering (see table.Cloner)
+func (lr *latexRenderer) Clone() (table.Renderer, error) {
+	c := *lr
+	c.bw, c.latexError, c.rowCells = nil, nil, nil
+	return &c, nil
+}
+
+// write does all the writing to the writer and handles errors by stopping any further writing
+// and returning the error
+func (lr *latexRenderer) write(s string) error {
+	if lr.latexError == nil {
+		_, lr.latexError = io.WriteString(lr.bw, s)
+	}
+	return lr.latexError
+}
+
+func (lr *latexRenderer) StartFile() error {
+	return lr.write(fmt.Sprintf(latexHeader, time.Now().Format("2006-01-02 15:04:05")))
+}
+
+func (lr *latexRenderer) EndFile() error {
+	return lr.Err()
+}
+
+func (lr *latexRenderer) StartTable(t *table.Table) error {
+	grid := t.ProcessedTableContents()
+	lr.rowCount, lr.rowNum = grid.RowCount(), 0
+	lr.long = lr.rowCount > longTableRowCount
+	lr.headerRows = 0
+	for r := 1; r <= lr.rowCount && grid.Row(r).IsHeader(); r++ {
+		lr.headerRows++
+	}
+	lr.inHeader = lr.headerRows > 0
+	lr.caption = ""
+	if t.Caption != nil {
+		var lines []string
+		for _, line := range t.Caption.Lines {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, lr.renderText(line))
+			}
+		}
+		lr.caption = strings.Join(lines, " ")
+	}
+	colSpec := "l" + strings.Repeat("c", max(grid.MaxFieldCount()-1, 0))
+	var b strings.Builder
+	if lr.long {
+		b.WriteString(`\begin{longtable}{` + colSpec + "}\n")
+		if lr.caption != "" {
+			b.WriteString(`\caption{` + lr.caption + `}\\` + "\n")
+		}
+	} else {
+		b.WriteString("\\begin{table}[htbp]\n\\centering\n")
+		if lr.caption != "" {
+			b.WriteString(`\caption{` + lr.caption + "}\n")
+		}
+		b.WriteString(`\begin{tabular}{` + colSpec + "}\n")
+	}
+	b.WriteString("\\toprule\n")
+	return lr.write(b.String())
+}
+
+func (lr *latexRenderer) EndTable(t *table.Table) error {
+	var b strings.Builder
+	b.WriteString("\\bottomrule\n")
+	if lr.long {
+		b.WriteString("\\end{longtable}\n")
+	} else {
+		b.WriteString("\\end{tabular}\n")
+	}
+	if t.Footnotes != nil {
+		var lines []string
+		for _, line := range t.Footnotes.Lines {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, lr.renderText(line))
+			}
+		}
+		if len(lines) > 0 {
+			b.WriteString("\\par\\smallskip\n{\\footnotesize\n" + strings.Join(lines, "\\\\\n") + "\\par}\n")
+		}
+	}
+	if !lr.long {
+		b.WriteString("\\end{table}\n")
+	}
+	b.WriteString("\n")
+	return lr.write(b.String())
+}
+
+func (lr *latexRenderer) StartRow(r *table.Row) error {
+	lr.rowCells = lr.rowCells[:0]
+	lr.rowNum++
+	return lr.Err()
+}
+
+//EndRow writes the buffered cells of the row
+func (lr *latexRenderer) EndRow(r *table.Row) error {
+	var (
+		b        strings.Builder
+		cells    []string
+		cmidrule []string //rules under column headings that span several columns
+		above    [][2]int //first and last cols of cells with a top border
+		below    [][2]int //first and last cols of cells with a bottom border
+	)
+	for i, c := range lr.rowCells {
+		col := i + 1
+		switch c.State() {
+		case table.CsHMerged: //covered by a \multicolumn to its left
+			continue
+		case table.CsVMerged, table.CsVHMerged: //covered by a \multirow above
+			if width := r.SpanWidth(col); width > 1 {
+				cells = append(cells, `\multicolumn{`+strconv.Itoa(width)+`}{c}{}`)
+			} else {
+				cells = append(cells, "")
+			}
+			continue
+		}
+		style := lr.styles.Resolve(c.Styles())
+		text := lr.cellText(c, style)
+		if c.RowSpan() > 1 {
+			text = `\multirow{` + strconv.Itoa(c.RowSpan()) + `}{*}{` + text + `}`
+		}
+		last := col + max(c.ColSpan(), 1) - 1
+		span := fmt.Sprintf("%d-%d", col, last)
+		switch align := columnAlign(style.Align); {
+		case c.ColSpan() > 1:
+			if align == "" {
+				align = "c"
+			}
+			text = `\multicolumn{` + strconv.Itoa(c.ColSpan()) + `}{` + align + `}{` + text + `}`
+			if lr.inHeader {
+				cmidrule = append(cmidrule, `\cmidrule(lr){`+span+`}`)
+			}
+		case align != "" && align != lr.colSpec(col):
+			text = `\multicolumn{1}{` + align + `}{` + text + `}`
+		}
+		if style.BorderTop != "" && style.BorderTop != "none" {
+			above = append(above, [2]int{col, last})
+		}
+		if style.BorderBottom != "" && style.BorderBottom != "none" {
+			below = append(below, [2]int{col, last})
+		}
+		cells = append(cells, text)
+	}
+	if len(above) > 0 {
+		b.WriteString(borderRules(above) + "\n")
+	}
+	b.WriteString(strings.Join(cells, " & ") + ` \\` + "\n")
+	if len(cmidrule) > 0 && lr.rowNum < lr.headerRows {
+		b.WriteString(strings.Join(cmidrule, " ") + "\n")
+	}
+	if len(below) > 0 {
+		b.WriteString(borderRules(below) + "\n")
+	}
+	if lr.inHeader && lr.rowNum == lr.headerRows { //last header row
+		lr.inHeader = false
+		b.WriteString("\\midrule\n")
+		if lr.long {
+			b.WriteString("\\endhead\n") //repeat header rows on each page
+		}
+	}
+	return lr.write(b.String())
+}
+
+func (lr *latexRenderer) OutputCell(c *table.Cell) error {
+	lr.rowCells = append(lr.rowCells, c)
+	return lr.Err()
+}
+
+//cellText returns the cell's text converted to LaTeX and formatted according to its style
+func (lr *latexRenderer) cellText(c *table.Cell, style types.Style) string {
+	text := lr.renderText(strings.TrimSpace(c.Text()))
+	if before, after, bw, aw, ok := c.Aligned(); ok { //line up the alignment points of the column
+		text = phantom(bw-utf8.RuneCountInString(before)) + lr.renderText(before) + lr.renderText(after) +
+			phantom(aw-utf8.RuneCountInString(after))
+	}
+	if style.Bold() {
+		text = `\textbf{` + text + `}`
+	}
+	if style.Italic {
+		text = `\textit{` + text + `}`
+	}
+	if indent := style.Indent + c.Indent(); indent > 0 {
+		text = `\hspace{` + strconv.Itoa(indent) + `em}` + text
+	}
+	if style.Shading != "" {
+		text = `\cellcolor[HTML]{` + style.Shading + `}` + text
+	}
+	return text
+}
+
+//phantom returns space as wide as n digits, which pads the parts of aligned cells to the same width
+func phantom(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return `\hphantom{` + strings.Repeat("0", n) + `}`
+}
+
+//borderRules returns the \cmidrule commands that draw borders over the given col ranges, which are in order;
+//adjacent ranges are drawn as one rule
+func borderRules(spans [][2]int) string {
+	var rules []string
+	for i := 0; i < len(spans); i++ {
+		first, last := spans[i][0], spans[i][1]
+		for ; i+1 < len(spans) && spans[i+1][0] == last+1; i++ {
+			last = spans[i+1][1]
+		}
+		rules = append(rules, fmt.Sprintf(`\cmidrule{%d-%d}`, first, last))
+	}
+	return strings.Join(rules, " ")
+}
+
+//colSpec returns the alignment of column col in the tabular column specification
+func (lr *latexRenderer) colSpec(col int) string {
+	if col == 1 {
+		return "l"
+	}
+	return "c"
+}
+
+//columnAlign returns the tabular column specification for a style alignment or "" if none
+func columnAlign(align string) string {
+	switch align {
+	case "left", "center", "right":
+		return align[:1]
+	}
+	return ""
+}
+
+//renderText converts inline markdown into LaTeX and escapes special characters
+func (lr *latexRenderer) renderText(s string) string {
+	if lr.settings != nil && lr.settings.MarkdownRender == "disabled" {
+		return escapeString(s)
+	}
+	var b strings.Builder
+	for _, r := range inline.Parse(s) {
+		text := escapeString(r.Text)
+		if r.Code {
+			text = `\texttt{` + text + `}`
+		}
+		if r.Bold {
+			text = `\textbf{` + text + `}`
+		}
+		if r.Italic {
+			text = `\textit{` + text + `}`
+		}
+		if r.Strike {
+			text = `\sout{` + text + `}` //requires package ulem
+		}
+		switch {
+		case r.Superscript:
+			text = `\textsuperscript{` + text + `}`
+		case r.Subscript:
+			text = `\textsubscript{` + text + `}`
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+//escapeString escapes LaTeX special characters
+func escapeString(s string) string {
+	return latexEscaper.Replace(s)
+}
+
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+	`<`, `\textless{}`,
+	`>`, `\textgreater{}`,
+)
@@ -0,0 +1,118 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package latex
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/drgo/rosewood"
+)
+
+const mergedTab = `+++
+Table 1. **Merged** cells
++++
+Brand|Some numbers||
+|Models|Price (US$)|
+AMC|3|4,215.67|
++++
+Prices in 1978 US$; 50% of models
++++
+merge row 1:2 col 1
+merge row 1 col 2:3
+style row 1:2 header
++++
+`
+
+const styledTab = `+++
+Table 2
++++
+Brand|Models|Price|
+AMC|3|4,215.67|
+Rambler|2|3,799.00|
++++
++++
+style row 2 italic shaded border-top
+style col 3 right
+style row 3 col 1 indent
+style row 3 col 3 bold left
++++
+`
+
+const alignedTab = `+++
+Table 3
++++
+Outcome|OR|
+Stroke|1.5|
+MI|12.25|
+Death|NA|
++++
++++
+align col 2 decimal
++++
+`
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{"merged cells", mergedTab, []string{
+			`% requires \usepackage{booktabs,multirow,longtable} and \usepackage[normalem]{ulem} in the preamble`,
+			`\caption{Table 1. \textbf{Merged} cells}`,
+			`\begin{tabular}{lcc}`,
+			`\multirow{2}{*}{Brand} & \multicolumn{2}{c}{Some numbers} \\`,
+			`\cmidrule(lr){2-3}`,
+			` & Models & Price (US\$) \\` + "\n" + `\midrule`,
+			`AMC & 3 & 4,215.67 \\` + "\n" + `\bottomrule`,
+			`Prices in 1978 US\$; 50\% of models`,
+		}},
+		{"styled cells", styledTab, []string{
+			`\cmidrule{1-3}` + "\n" + `\cellcolor[HTML]{D9D9D9}\textit{AMC} & `,
+			`\multicolumn{1}{r}{\cellcolor[HTML]{D9D9D9}\textit{4,215.67}} \\`,
+			`\hspace{1em}Rambler & 2 & \multicolumn{1}{l}{\textbf{3,799.00}} \\`,
+		}},
+		{"aligned cells", alignedTab, []string{
+			`Stroke & \hphantom{0}1.5\hphantom{0} \\`,
+			`MI & 12.25 \\`,
+			`Death & NA \\`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := rosewood.DefaultJob(rosewood.DefaultSettings())
+			ri := rosewood.NewInterpreter(job)
+			file, err := ri.Parse(strings.NewReader(tt.src), tt.name)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			lr, _ := NewLaTeXRenderer()
+			w := &bytes.Buffer{}
+			if err := ri.Render(w, file, lr); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(w.String(), want) {
+					t.Errorf("wanted string [%s] was not found in\n%s", want, w.String())
+				}
+			}
+		})
+	}
+}
+
+func TestEscapeString(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{`50% & <0.05`, `50\% \& \textless{}0.05`},
+		{`a_b {c} #1 ~2^3 \n`, `a\_b \{c\} \#1 \textasciitilde{}2\textasciicircum{}3 \textbackslash{}n`},
+	}
+	for _, tt := range tests {
+		if got := escapeString(tt.s); got != tt.want {
+			t.Errorf("escapeString(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+//Package markdown implements a table.Renderer that writes Rosewood tables as GitHub-flavoured markdown pipe tables
+package markdown
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/drgo/rosewood"
+	"github.com/drgo/rosewood/table"
+	"github.com/drgo/rosewood/types"
+)
+
+//init, run automatically, registers markdown renderer with Rosewood
+func init() {
+	config := rosewood.RendererConfig{
+		Name:     "md",
+		Renderer: makeMarkdownRenderer,
+	}
+	rosewood.RegisterRenderer(&config)
+}
+
+//markdownRenderer implements table.Renderer for markdown output.
+//Pipe tables cannot span cells, so merged cells are left empty and their text is written to the top-left cell
+type markdownRenderer struct {
+	bw       io.Writer
+	settings *types.RosewoodSettings
+	tables   []*table.Table
+	mdError  error    //tracks errors
+	rowCells []string //text of the cells of the current row
+	rowNum   int      //number of the current row
+
+	styles *types.StyleRegistry
+	aligns []string //alignment of each column of the current table, if shared by its styled cells
+}
+
+//makeMarkdownRenderer factory function according to the renderer registration requirements
+func makeMarkdownRenderer() (table.Renderer, error) {
+	return NewMarkdownRenderer()
+}
+
+//NewMarkdownRenderer create a new markdownRenderer and return it as a Renderer
+func NewMarkdownRenderer() (table.Renderer, error) {
+	return &markdownRenderer{styles: types.DefaultStyleRegistry()}, nil
+}
+
+func (mr *markdownRenderer) SetWriter(w io.Writer) error {
+	mr.bw = w
+	return nil
+}
+
+func (mr *markdownRenderer) SetSettings(settings *types.RosewoodSettings) error {
+	mr.settings = settings
+	var err error
+	mr.styles, err = types.LoadStyleRegistry(settings.StylesFileName)
+	return err
+}
+
+func (mr *markdownRenderer) SetTables(tables []*table.Table) error {
+	mr.tables = tables
+	return nil
+}
+
+func (mr *markdownRenderer) Err() error {
+	return mr.mdError
+}
+
+//Clone returns a copy of the renderer for rendering tables concurrently; implements table.Cloner
+func (mr *markdownRenderer) Clone() (table.Renderer, error) {
+	c := *mr
+	c.bw, c.mdError, c.rowCells, c.aligns = nil, nil, nil, nil
+	return &c, nil
+}
+
+// write does all the writing to the writer and handles errors by stopping any further writing
+// and returning the error
+func (mr *markdownRenderer) write(s string) error {
+	if mr.mdError == nil {
+		_, mr.mdError = io.WriteString(mr.bw, s)
+	}
+	return mr.mdError
+}
+
+func (mr *markdownRenderer) StartFile() error {
+	return mr.Err()
+}
+
+func (mr *markdownRenderer) EndFile() error {
+	return mr.Err()
+}
+
+func (mr *markdownRenderer) StartTable(t *table.Table) error {
+	mr.rowNum = 0
+	mr.aligns = mr.columnAligns(t)
+	if t.Caption != nil {
+		if caption := joinLines(t.Caption); caption != "" {
+			mr.write("**" + caption + "**\n\n")
+		}
+	}
+	return mr.Err()
+}
+
+func (mr *markdownRenderer) EndTable(t *table.Table) error {
+	if t.Footnotes != nil {
+		mr.write("\n")
+		for _, line := range t.Footnotes.Lines {
+			if line = strings.TrimSpace(line); line != "" {
+				mr.write(line + "  \n") //two trailing spaces force a line break
+			}
+		}
+	}
+	return mr.write("\n")
+}
+
+func (mr *markdownRenderer) StartRow(r *table.Row) error {
+	mr.rowCells = mr.rowCells[:0]
+	mr.rowNum++
+	return mr.Err()
+}
+
+func (mr *markdownRenderer) EndRow(r *table.Row) error {
+	mr.write("| " + strings.Join(mr.rowCells, " | ") + " |\n")
+	if mr.rowNum == 1 { //the first row is always the header row in a pipe table
+		marks := make([]string, len(mr.rowCells))
+		for i := range marks {
+			marks[i] = "---"
+			if i < len(mr.aligns) {
+				switch mr.aligns[i] {
+				case "left":
+					marks[i] = ":---"
+				case "center":
+					marks[i] = ":---:"
+				case "right":
+					marks[i] = "---:"
+				}
+			}
+		}
+		mr.write("| " + strings.Join(marks, " | ") + " |\n")
+	}
+	return mr.Err()
+}
+
+func (mr *markdownRenderer) OutputCell(c *table.Cell) error {
+	text := ""
+	if !c.Merged() {
+		text = escapeString(strings.TrimSpace(c.Text()))
+		if before, after, bw, aw, ok := c.Aligned(); ok { //line up the alignment points of the column
+			text = escapeString(pad(before, bw, true) + pad(after, aw, false))
+		}
+		style := mr.styles.Resolve(c.Styles())
+		if text != "" && style.Italic {
+			text = "*" + text + "*"
+		}
+		if text != "" && (style.Bold() || c.Header() && mr.rowNum > 1) { //header rows after the first are shown in bold
+			text = "**" + text + "**"
+		}
+		if text != "" { //leading spaces are ignored in markdown tables
+			text = strings.Repeat("&emsp;", style.Indent+c.Indent()) + text
+		}
+	}
+	mr.rowCells = append(mr.rowCells, text)
+	return mr.Err()
+}
+
+//columnAligns returns the alignment of each column of t, if all its styled cells below the first row agree on
+//one. Pipe tables align whole columns only, so alignments of single cells are otherwise ignored
+func (mr *markdownRenderer) columnAligns(t *table.Table) []string {
+	grid := t.ProcessedTableContents()
+	if grid == nil {
+		return nil
+	}
+	var aligns []string
+	mixed := map[int]bool{}
+	for i, r := range grid.Rows() {
+		if i == 0 { //the first row is the header row of the pipe table
+			continue
+		}
+		for j, c := range r.Cells() {
+			if c.Merged() {
+				continue
+			}
+			align := mr.styles.Resolve(c.Styles()).Align
+			if align == "" {
+				continue
+			}
+			for len(aligns) <= j {
+				aligns = append(aligns, "")
+			}
+			switch {
+			case mixed[j]:
+			case aligns[j] == "":
+				aligns[j] = align
+			case aligns[j] != align:
+				aligns[j], mixed[j] = "", true
+			}
+		}
+	}
+	return aligns
+}
+
+func joinLines(s *types.Section) string {
+	var lines []string
+	for _, line := range s.Lines {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, " ")
+}
+
+//figureSpace has the width of a digit; unlike spaces, it is neither trimmed from cells nor collapsed
+const figureSpace = "\u2007"
+
+//pad pads s with figure spaces on the left (right-aligning it) or the right to width runes
+func pad(s string, width int, left bool) string {
+	gap := width - utf8.RuneCountInString(s)
+	if gap <= 0 {
+		return s
+	}
+	if left {
+		return strings.Repeat(figureSpace, gap) + s
+	}
+	return s + strings.Repeat(figureSpace, gap)
+}
+
+//escapeString escapes pipes which would otherwise start a new cell
+func escapeString(s string) string {
+	return strings.Replace(s, "|", `\|`, -1)
+}
@@ -0,0 +1,120 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package markdown
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/drgo/rosewood"
+)
+
+const mergedTab = `+++
+Car brands
++++
+Brand|Some numbers||
+|Models|Price|
+AMC|3|4,215.67|
++++
+Prices in US$
++++
+merge row 1:2 col 1
+merge row 1 col 2:3
+style row 1:2 header
++++
+`
+
+func TestRender(t *testing.T) {
+	const want = `**Car brands**
+
+| Brand | Some numbers |  |
+| --- | --- | --- |
+|  | **Models** | **Price** |
+| AMC | 3 | 4,215.67 |
+
+Prices in US$  
+
+`
+	job := rosewood.DefaultJob(rosewood.DefaultSettings())
+	ri := rosewood.NewInterpreter(job)
+	file, err := ri.Parse(strings.NewReader(mergedTab), "test")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	mr, _ := NewMarkdownRenderer()
+	w := &bytes.Buffer{}
+	if err := ri.Render(w, file, mr); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if w.String() != want {
+		t.Errorf("Render() = \n%q, want \n%q", w.String(), want)
+	}
+}
+
+func TestRenderAligned(t *testing.T) {
+	const src = `+++
+Table 1
++++
+Outcome|OR|
+Stroke|1.5|
+MI|12.25|
+Death|NA|
++++
++++
+align col 2 decimal
++++
+`
+	const fs = "\u2007" //figure space
+	want := "| Outcome | OR |\n| --- | --- |\n" +
+		"| Stroke | " + fs + "1.5" + fs + " |\n" +
+		"| MI | 12.25 |\n" +
+		"| Death | NA |\n"
+	ri := rosewood.NewInterpreter(rosewood.DefaultJob(rosewood.DefaultSettings()))
+	file, err := ri.Parse(strings.NewReader(src), "test")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	mr, _ := NewMarkdownRenderer()
+	w := &bytes.Buffer{}
+	if err := ri.Render(w, file, mr); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(w.String(), want) {
+		t.Errorf("Render() = \n%q, want it to contain \n%q", w.String(), want)
+	}
+}
+
+func TestRenderStyles(t *testing.T) {
+	const src = `+++
+Table 1
++++
+Outcome|Cases|Deaths|
+Stroke|15|3|
+Total|20|5|
++++
++++
+style col 2 right
+style row 2 col 2 center
+style col 3 center
+style row 3 bold
+style row 3 col 1 italic
++++
+`
+	const want = "| Outcome | Cases | Deaths |\n| --- | --- | :---: |\n" +
+		"| Stroke | 15 | 3 |\n" +
+		"| ***Total*** | **20** | **5** |\n"
+	ri := rosewood.NewInterpreter(rosewood.DefaultJob(rosewood.DefaultSettings()))
+	file, err := ri.Parse(strings.NewReader(src), "test")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	mr, _ := NewMarkdownRenderer()
+	w := &bytes.Buffer{}
+	if err := ri.Render(w, file, mr); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(w.String(), want) {
+		t.Errorf("Render() = \n%q, want it to contain \n%q", w.String(), want)
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+//Package text implements a table.Renderer that draws Rosewood tables as monospaced box-drawing grids
+package text
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/drgo/rosewood"
+	"github.com/drgo/rosewood/renderers/internal/inline"
+	"github.com/drgo/rosewood/table"
+	"github.com/drgo/rosewood/types"
+)
+
+//init, run automatically, registers text renderer with Rosewood
+func init() {
+	config := rosewood.RendererConfig{
+		Name:     "text",
+		Renderer: makeTextRenderer,
+	}
+	rosewood.RegisterRenderer(&config)
+}
+
+//indentText is written before the text of a cell for each of its indent levels
+const indentText = "  "
+
+//boxJunctions holds the box-drawing char for each combination of lines meeting at a junction
+//indexed by up=1|down=2|left=4|right=8
+var boxJunctions = []rune{' ', '│', '│', '│', '─', '┘', '┐', '┤', '─', '└', '┌', '├', '─', '┴', '┬', '┼'}
+
+//textRenderer implements table.Renderer for plain text output.
+//The whole table is buffered because column widths depend on all of its cells
+type textRenderer struct {
+	bw        io.Writer
+	settings  *types.RosewoodSettings
+	styles    *types.StyleRegistry
+	tables    []*table.Table
+	textError error           //tracks errors
+	rows      [][]*table.Cell //cells of the current table
+	tableNum  int             //number of the current table
+}
+
+//makeTextRenderer factory function according to the renderer registration requirements
+func makeTextRenderer() (table.Renderer, error) {
+	return NewTextRenderer()
+}
+
+//NewTextRenderer create a new textRenderer and return it as a Renderer
+func NewTextRenderer() (table.Renderer, error) {
+	return &textRenderer{styles: types.DefaultStyleRegistry()}, nil
+}
+
+func (tr *textRenderer) SetWriter(w io.Writer) error {
+	tr.bw = w
+	return nil
+}
+
+func (tr *textRenderer) SetSettings(settings *types.RosewoodSettings) error {
+	tr.settings = settings
+	var err error
+	tr.styles, err = types.LoadStyleRegistry(settings.StylesFileName)
+	return err
+}
+
+func (tr *textRenderer) SetTables(tables []*table.Table) error {
+	tr.tables = tables
+	return nil
+}
+
+func (tr *textRenderer) Err() error {
+	return tr.textError
+}
+
+//Clone implements table.Cloner; buffered cells are not shared with the copy
+func (tr *textRenderer) Clone() (table.Renderer, error) {
+	c := *tr
+	c.bw, c.textError, c.rows = nil, nil, nil
+	return &c, nil
+}
+
+// write does all the writing to the writer and handles errors by stopping any further writing
+// and returning the error
+func (tr *textRenderer) write(s string) error {
+	if tr.textError == nil {
+		_, tr.textError = io.WriteString(tr.bw, s)
+	}
+	return tr.textError
+}
+
+func (tr *textRenderer) StartFile() error {
+	tr.tableNum = 0
+	return tr.Err()
+}
+
+func (tr *textRenderer) EndFile() error {
+	return tr.Err()
+}
+
+func (tr *textRenderer) StartTable(t *table.Table) error {
+	num := tr.tableNum + 1
+	if i := table.IndexOf(tr.tables, t); i >= 0 { //clones rendering concurrently only see some of the tables
+		num = i + 1
+	}
+	if num > 1 {
+		tr.write("\n")
+	}
+	tr.tableNum = num
+	tr.rows = tr.rows[:0]
+	if t.Caption != nil {
+		for _, line := range t.Caption.Lines {
+			if line = strings.TrimSpace(line); line != "" {
+				tr.write(tr.renderText(line) + "\n")
+			}
+		}
+	}
+	return tr.Err()
+}
+
+func (tr *textRenderer) EndTable(t *table.Table) error {
+	tr.write(tr.drawGrid())
+	if t.Footnotes != nil {
+		for _, line := range t.Footnotes.Lines {
+			if line = strings.TrimSpace(line); line != "" {
+				tr.write(tr.renderText(line) + "\n")
+			}
+		}
+	}
+	return tr.Err()
+}
+
+func (tr *textRenderer) StartRow(r *table.Row) error {
+	tr.rows = append(tr.rows, nil)
+	return tr.Err()
+}
+
+func (tr *textRenderer) EndRow(r *table.Row) error {
+	return tr.Err()
+}
+
+func (tr *textRenderer) OutputCell(c *table.Cell) error {
+	last := len(tr.rows) - 1
+	tr.rows[last] = append(tr.rows[last], c)
+	return tr.Err()
+}
+
+//renderText strips inline markdown delimiters unless markdown rendering is disabled
+func (tr *textRenderer) renderText(s string) string {
+	if tr.settings != nil && tr.settings.MarkdownRender == "disabled" {
+		return s
+	}
+	return inline.PlainText(s)
+}
+
+//gridCell describes the visible cell that covers a grid position
+type gridCell struct {
+	row, col         int //zero-based coordinates of the top-left position of the cell
+	rowSpan, colSpan int
+	text             string
+	align            string //left, right or center
+}
+
+//drawGrid draws the buffered table. Borders are drawn between two grid positions only if they
+//belong to different visible cells, which leaves merged areas open
+func (tr *textRenderer) drawGrid() string {
+	rowCount := len(tr.rows)
+	if rowCount == 0 {
+		return ""
+	}
+	colCount := len(tr.rows[0])
+	owners := make([][]*gridCell, rowCount)
+	for r := range owners {
+		owners[r] = make([]*gridCell, colCount)
+	}
+	var cells []*gridCell
+	for r, row := range tr.rows {
+		for c, cell := range row {
+			if cell.Merged() || c >= colCount {
+				continue
+			}
+			style := tr.styles.Resolve(cell.Styles())
+			gc := &gridCell{row: r, col: c, rowSpan: max(cell.RowSpan(), 1), colSpan: max(cell.ColSpan(), 1),
+				text: tr.renderText(strings.TrimSpace(cell.Text())), align: alignment(style)}
+			if before, after, bw, aw, ok := cell.Aligned(); ok { //line up the alignment points of the column
+				gc.text = pad(tr.renderText(before), bw, "right") + pad(tr.renderText(after), aw, "left")
+			}
+			gc.text = strings.Repeat(indentText, style.Indent+cell.Indent()) + gc.text
+			for i := r; i < r+gc.rowSpan && i < rowCount; i++ {
+				for j := c; j < c+gc.colSpan && j < colCount; j++ {
+					owners[i][j] = gc
+				}
+			}
+			cells = append(cells, gc)
+		}
+	}
+	widths := columnWidths(cells, colCount)
+	//horizontal returns true if a line separates grid rows b-1 and b at col c
+	horizontal := func(b, c int) bool {
+		return b == 0 || b == rowCount || owners[b-1][c] != owners[b][c]
+	}
+	//vertical returns true if a line separates grid cols j-1 and j at row r
+	vertical := func(r, j int) bool {
+		return j == 0 || j == colCount || owners[r][j-1] != owners[r][j]
+	}
+	junction := func(b, j int) rune {
+		mask := 0
+		if b > 0 && vertical(b-1, j) {
+			mask |= 1
+		}
+		if b < rowCount && vertical(b, j) {
+			mask |= 2
+		}
+		if j > 0 && horizontal(b, j-1) {
+			mask |= 4
+		}
+		if j < colCount && horizontal(b, j) {
+			mask |= 8
+		}
+		return boxJunctions[mask]
+	}
+	var sb strings.Builder
+	for b := 0; b <= rowCount; b++ {
+		//border line above grid row b
+		for j := 0; j < colCount; j++ {
+			sb.WriteRune(junction(b, j))
+			fill := " "
+			if horizontal(b, j) {
+				fill = "─"
+			}
+			sb.WriteString(strings.Repeat(fill, widths[j]+2))
+		}
+		sb.WriteRune(junction(b, colCount))
+		sb.WriteString("\n")
+		if b == rowCount {
+			break
+		}
+		//contents of grid row b
+		sb.WriteString("│")
+		for j := 0; j < colCount; {
+			gc := owners[b][j]
+			if gc == nil { //should not happen in a valid grid
+				sb.WriteString(strings.Repeat(" ", widths[j]+2) + "│")
+				j++
+				continue
+			}
+			width := widths[j]
+			for k := j + 1; k < gc.col+gc.colSpan && k < colCount; k++ {
+				width += widths[k] + 3
+			}
+			text := ""
+			if gc.row == b { //text is shown in the top row of a merged cell
+				text = gc.text
+			}
+			sb.WriteString(" " + pad(text, width, gc.align) + " │")
+			j = gc.col + gc.colSpan
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+//columnWidths returns the width of each column so that it fits its widest cell.
+//Cells spanning several columns widen the last column of their span if needed
+func columnWidths(cells []*gridCell, colCount int) []int {
+	widths := make([]int, colCount)
+	for _, gc := range cells {
+		if gc.colSpan == 1 {
+			widths[gc.col] = max(widths[gc.col], utf8.RuneCountInString(gc.text))
+		}
+	}
+	for _, gc := range cells {
+		if gc.colSpan == 1 {
+			continue
+		}
+		last := min(gc.col+gc.colSpan, colCount) - 1
+		available := 0
+		for j := gc.col; j <= last; j++ {
+			available += widths[j]
+		}
+		available += 3 * (last - gc.col) //room taken by the omitted " │ " separators
+		if need := utf8.RuneCountInString(gc.text); need > available {
+			widths[last] += need - available
+		}
+	}
+	return widths
+}
+
+//alignment returns the alignment set by a cell's style; cells are left-aligned by default
+func alignment(style types.Style) string {
+	if style.Align == "" {
+		return "left"
+	}
+	return style.Align
+}
+
+func pad(s string, width int, align string) string {
+	gap := width - utf8.RuneCountInString(s)
+	if gap <= 0 {
+		return s
+	}
+	switch align {
+	case "right":
+		return strings.Repeat(" ", gap) + s
+	case "center":
+		return strings.Repeat(" ", gap/2) + s + strings.Repeat(" ", gap-gap/2)
+	default:
+		return s + strings.Repeat(" ", gap)
+	}
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
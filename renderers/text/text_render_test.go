@@ -0,0 +1,136 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package text
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/drgo/rosewood"
+)
+
+const mergedTab = `+++
+Car brands
++++
+Brand|Some numbers||
+|Models|Average price|
+AMC|3|4,215.67|
+Audi|2|7,992.50|
++++
+Prices in US$
++++
+merge row 1:2 col 1
+merge row 1 col 2:3
+style row 1:2 header
+style row 3:4 col 2:3 right
++++
+`
+
+func TestRender(t *testing.T) {
+	const want = `Car brands
+┌───────┬────────────────────────┐
+│ Brand │ Some numbers           │
+│       ├────────┬───────────────┤
+│       │ Models │ Average price │
+├───────┼────────┼───────────────┤
+│ AMC   │      3 │      4,215.67 │
+├───────┼────────┼───────────────┤
+│ Audi  │      2 │      7,992.50 │
+└───────┴────────┴───────────────┘
+Prices in US$
+`
+	job := rosewood.DefaultJob(rosewood.DefaultSettings())
+	ri := rosewood.NewInterpreter(job)
+	file, err := ri.Parse(strings.NewReader(mergedTab), "test")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	tr, _ := NewTextRenderer()
+	w := &bytes.Buffer{}
+	if err := ri.Render(w, file, tr); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if w.String() != want {
+		t.Errorf("Render() = \n%s, want \n%s", w.String(), want)
+	}
+}
+
+func TestRenderAligned(t *testing.T) {
+	const src = `+++
+Table 1
++++
+Outcome|N|OR (95% CI)|
+Stroke|12,345.5|1.20 (0.90-1.50)|
+MI|7|10.5 (2.1-52.3)|
+Death|.25|NA|
++++
++++
+header row 1
+align col 2 decimal
+align col 3 on "("
++++
+`
+	const want = `Table 1
+┌─────────┬───────────┬──────────────────┐
+│ Outcome │ N         │ OR (95% CI)      │
+├─────────┼───────────┼──────────────────┤
+│ Stroke  │ 12,345.5  │ 1.20 (0.90-1.50) │
+├─────────┼───────────┼──────────────────┤
+│ MI      │      7    │ 10.5 (2.1-52.3)  │
+├─────────┼───────────┼──────────────────┤
+│ Death   │       .25 │    NA            │
+└─────────┴───────────┴──────────────────┘
+`
+	job := rosewood.DefaultJob(rosewood.DefaultSettings())
+	ri := rosewood.NewInterpreter(job)
+	file, err := ri.Parse(strings.NewReader(src), "test")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	tr, _ := NewTextRenderer()
+	w := &bytes.Buffer{}
+	if err := ri.Render(w, file, tr); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if w.String() != want {
+		t.Errorf("Render() = \n%s, want \n%s", w.String(), want)
+	}
+}
+
+func TestRenderIndentLevels(t *testing.T) {
+	const src = `+++
+Table 1
++++
+Statin|OR|
+  No|ref|
+    Low|1.00|
++++
++++
++++
+`
+	const want = `Table 1
+┌─────────┬──────┐
+│ Statin  │ OR   │
+├─────────┼──────┤
+│   No    │ ref  │
+├─────────┼──────┤
+│     Low │ 1.00 │
+└─────────┴──────┘
+`
+	settings := rosewood.DefaultSettings()
+	settings.IndentLevels = true
+	ri := rosewood.NewInterpreter(rosewood.DefaultJob(settings))
+	file, err := ri.Parse(strings.NewReader(src), "test")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	tr, _ := NewTextRenderer()
+	w := &bytes.Buffer{}
+	if err := ri.Render(w, file, tr); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if w.String() != want {
+		t.Errorf("Render() = \n%s, want \n%s", w.String(), want)
+	}
+}
@@ -27,3 +27,26 @@ type Renderer interface {
 	EndRow(r *Row) error
 	OutputCell(c *Cell) error
 }
+
+//DocumentSetter is an optional interface implemented by renderers that need page settings (eg docx)
+type DocumentSetter interface {
+	SetDocument(doc *types.Document) error
+}
+
+//JobSetter is an optional interface implemented by renderers that use job metadata, eg in html templates.
+//fileName is the name of the rendered Rosewood file
+type JobSetter interface {
+	SetJob(job *types.Job, fileName string) error
+}
+
+//Cloner is an optional interface implemented by renderers that can render tables concurrently. Clone returns
+//a new renderer that shares the settings and tables of the original but has its own writer and per-table state
+type Cloner interface {
+	Clone() (Renderer, error)
+}
+
+//Warner is an optional interface implemented by renderers that find problems that do not stop rendering,
+//eg style names that are not defined in a stylesheet. Warnings is called after SetTables
+type Warner interface {
+	Warnings() []string
+}
@@ -11,6 +11,7 @@ import (
 //Row holds info on a Rosewood table row
 type Row struct {
 	cells []*Cell
+	head  bool //row is part of the table head, set by header row commands
 }
 
 func newBlankRow(row int, colCount int) *Row {
@@ -18,12 +19,12 @@ func newBlankRow(row int, colCount int) *Row {
 	for i := 0; i < colCount; i++ {
 		cells[i] = NewCell("", row, i+1)
 	}
-	return &Row{cells}
+	return &Row{cells: cells}
 }
 
 //MakeRow for testing
 func makeRow(cells ...*Cell) *Row {
-	return &Row{cells}
+	return &Row{cells: cells}
 }
 
 func (r *Row) String() string {
@@ -35,10 +36,60 @@ func (r *Row) String() string {
 	return b.String()
 }
 
-func (r *Row) cellCount() int {
+//CellCount returns the number of cells in the row
+func (r *Row) CellCount() int {
 	return len(r.cells)
 }
 
+//Cells returns the cells of the row in order. The returned slice is a copy but the cells are shared
+//and must be treated as read-only
+func (r *Row) Cells() []*Cell {
+	cells := make([]*Cell, len(r.cells))
+	copy(cells, r.cells)
+	return cells
+}
+
+//Cell returns the cell in column col (1-based) or nil if there is no such cell
+func (r *Row) Cell(col int) *Cell {
+	if col < 1 || col > len(r.cells) {
+		return nil
+	}
+	return r.cells[col-1]
+}
+
+//IsHeader returns true if all visible (not merged) cells in the row are header cells
+func (r *Row) IsHeader() bool {
+	found := false
+	for _, c := range r.cells {
+		if c.Merged() {
+			continue
+		}
+		if !c.header {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+//Head returns true if the row belongs to the table head (eg html thead) as set by a header row command
+func (r *Row) Head() bool {
+	return r.head
+}
+
+//SpanWidth returns the number of columns occupied by the cell in column col (1-based), ie
+//the cell itself and any horizontally merged cells that immediately follow it
+func (r *Row) SpanWidth(col int) int {
+	if col < 1 || col > len(r.cells) {
+		return 0
+	}
+	width := 1
+	for c := col; c < len(r.cells) && r.cells[c].state == CsHMerged; c++ {
+		width++
+	}
+	return width
+}
+
 //CellState describes whether a cell is merged, spanned or otherwise
 type CellState int
 
@@ -65,7 +116,14 @@ type Cell struct {
 	state            CellState
 	rowSpan, colSpan int
 	styleList        []string
-	header           bool //optimization for header cells
+	header           bool       //optimization for header cells
+	scope            string     //col or row for cells marked by header commands
+	headerCells      []*Cell    //header cells that apply to this cell
+	source           *Cell      //cell in Table.Contents whose text was copied into this grid cell
+	alignParts       []string   //text before and after the alignment point set by an align command, if any
+	alignGroup       alignGroup //cells aligned together with this one
+	alignWidths      [2]int     //widths of the widest parts before and after the alignment point in alignGroup
+	indent           int        //indent level set from leading spaces, see Table.IndentLevels
 }
 
 //NewCell returns a pointer to a new Cell
@@ -104,8 +162,43 @@ func (c *Cell) Header() bool {
 	return c.header
 }
 
+//Row returns the row number (1-based) of the cell
+func (c *Cell) Row() int {
+	return c.row
+}
+
+//Col returns the column number (1-based) of the cell
+func (c *Cell) Col() int {
+	return c.col
+}
+
+//Source returns the cell in Table.Contents that supplied the text of this processed cell. It returns nil
+//for cells of Table.Contents itself and for processed cells that received no text, eg horizontally merged cells
+//or cells beyond the end of a short source row
+func (c *Cell) Source() *Cell {
+	return c.source
+}
+
+//Scope returns "col" for cells in header rows, "row" for cells in header columns and "" otherwise
+func (c *Cell) Scope() string {
+	return c.scope
+}
+
+//HeaderCells returns the header cells that describe this cell, outermost first.
+//Only available for tables with complex headers (see Table.ComplexHeaders)
+func (c *Cell) HeaderCells() []*Cell {
+	return c.headerCells
+}
+
+//Indent returns the indent level of the cell: 0 for cells that are not indented, 1 for the least indented
+//cells in the first column, 2 for the next indentation depth and so on. Only set if Table.IndentLevels is true
+func (c *Cell) Indent() int {
+	return c.indent
+}
+
+//Styles returns a copy of the style names of the cell; use AddStyle to change them
 func (c *Cell) Styles() []string {
-	return c.styleList
+	return append([]string(nil), c.styleList...)
 }
 
 func (c *Cell) Merged() bool {
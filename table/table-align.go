@@ -0,0 +1,82 @@
+package table
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/drgo/rosewood/types"
+)
+
+//alignGroup identifies cells that are aligned together: cells of the same column aligned on the same point
+type alignGroup struct {
+	col   int
+	align string
+}
+
+//applyAlignments splits the text of the cells that each align command applies to at their alignment point
+//and records the widths of the widest parts in each column so that renderers can pad them. Header cells, merged
+//cells and cells without an alignment point (eg NA in a decimal column or empty cells) are left as they are
+func (t *Table) applyAlignments(ctx context.Context) error {
+	widths := make(map[alignGroup][2]int)
+	var aligned []*Cell
+	for _, cmd := range t.CmdList {
+		if cmd.ID() != types.KwAlign {
+			continue
+		}
+		rList, err := cmd.Span().ExpandSpanToRanges()
+		if err != nil {
+			return err
+		}
+		if err := t.grid.ValidateRanges(rList); err != nil {
+			return err
+		}
+		a := cmd.Alignment()
+		for _, ra := range rList {
+			for i := ra.TopLeft.Row; i <= ra.BottomRight.Row; i++ {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				for j := ra.TopLeft.Col; j <= ra.BottomRight.Col; j++ {
+					cell := t.grid.CellorPanic(i, j)
+					if cell.header || cell.Merged() {
+						continue
+					}
+					before, after, ok := a.Split(strings.TrimSpace(cell.text))
+					if !ok {
+						continue
+					}
+					if cell.alignParts == nil {
+						aligned = append(aligned, cell)
+					}
+					cell.alignParts = []string{before, after}
+					cell.alignGroup = alignGroup{col: j, align: a.String()}
+				}
+			}
+		}
+	}
+	for _, cell := range aligned {
+		w := widths[cell.alignGroup]
+		for k, part := range cell.alignParts {
+			if n := utf8.RuneCountInString(part); n > w[k] {
+				w[k] = n
+			}
+		}
+		widths[cell.alignGroup] = w
+	}
+	for _, cell := range aligned {
+		cell.alignWidths = widths[cell.alignGroup]
+	}
+	return nil
+}
+
+//Aligned returns the text of a cell in an align command split at its alignment point, and the widths (in chars)
+//of the widest parts before and after that point among the cells of the column aligned on the same point.
+//Renderers line up the cells by padding before on the left and after on the right. ok is false if the cell
+//is not aligned
+func (c *Cell) Aligned() (before, after string, beforeWidth, afterWidth int, ok bool) {
+	if c.alignParts == nil {
+		return "", "", 0, 0, false
+	}
+	return c.alignParts[0], c.alignParts[1], c.alignWidths[0], c.alignWidths[1], true
+}
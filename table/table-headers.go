@@ -0,0 +1,133 @@
+package table
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/drgo/rosewood/types"
+)
+
+//applyHeaders applies header commands to the grid: header row commands mark rows as part of the table head
+//and their cells as column headers; header col commands mark cells as row headers
+func (t *Table) applyHeaders(ctx context.Context) error {
+	for _, cmd := range t.CmdList {
+		if cmd.ID() != types.KwHeader {
+			continue
+		}
+		rList, err := cmd.Span().ExpandSpanToRanges()
+		if err != nil {
+			return err
+		}
+		if err := t.grid.ValidateRanges(rList); err != nil {
+			return err
+		}
+		headRows := cmd.SpanSegment("row") != nil //eg header row 1:2; otherwise header col 1
+		for _, ra := range rList {
+			for i := ra.TopLeft.Row; i <= ra.BottomRight.Row; i++ {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if headRows {
+					t.grid.Row(i).head = true
+				}
+				for j := ra.TopLeft.Col; j <= ra.BottomRight.Col; j++ {
+					cell := t.grid.CellorPanic(i, j)
+					cell.header = true
+					switch {
+					case headRows:
+						cell.scope = "col"
+					case cell.scope == "": //cells in header rows remain column headers
+						cell.scope = "row"
+					}
+				}
+			}
+		}
+	}
+	//the table head must be a block of rows at the top of the table
+	inHead := true
+	for i, row := range t.grid.rows {
+		if row.head && !inHead {
+			return fmt.Errorf("invalid header row %d: header rows must be the first rows of the table", i+1)
+		}
+		inHead = row.head
+	}
+	t.complexHeaders = t.hasComplexHeaders()
+	if t.complexHeaders {
+		t.linkHeaderCells()
+	}
+	return nil
+}
+
+//hasComplexHeaders returns true if the table has more than one header row or header cells that span several
+//rows or columns. Such tables need id/headers attributes because scope alone does not describe them
+func (t *Table) hasComplexHeaders() bool {
+	headRows := 0
+	for _, row := range t.grid.rows {
+		if row.head {
+			headRows++
+		}
+	}
+	if headRows > 1 {
+		return true
+	}
+	complex := false
+	t.grid.ForEachCell(func(c *Cell) error {
+		if c.scope != "" && !c.Merged() && (c.rowSpan > 1 || c.colSpan > 1) {
+			complex = true
+		}
+		return nil
+	})
+	return complex
+}
+
+//linkHeaderCells records for each visible cell the column headers above it and the row headers to its left
+func (t *Table) linkHeaderCells() {
+	var colHeaders, rowHeaders []*Cell
+	t.grid.ForEachCell(func(c *Cell) error {
+		switch {
+		case c.Merged():
+		case c.scope == "col":
+			colHeaders = append(colHeaders, c)
+		case c.scope == "row":
+			rowHeaders = append(rowHeaders, c)
+		}
+		return nil
+	})
+	t.grid.ForEachCell(func(c *Cell) error {
+		if c.Merged() {
+			return nil
+		}
+		c.headerCells = nil
+		for _, h := range colHeaders {
+			if h.lastRow() < c.row && overlaps(h.col, h.lastCol(), c.col, c.lastCol()) {
+				c.headerCells = append(c.headerCells, h)
+			}
+		}
+		for _, h := range rowHeaders {
+			if h.lastCol() < c.col && overlaps(h.row, h.lastRow(), c.row, c.lastRow()) {
+				c.headerCells = append(c.headerCells, h)
+			}
+		}
+		return nil
+	})
+}
+
+//lastRow returns the number of the last row covered by the cell
+func (c *Cell) lastRow() int {
+	if c.rowSpan > 1 {
+		return c.row + c.rowSpan - 1
+	}
+	return c.row
+}
+
+//lastCol returns the number of the last column covered by the cell
+func (c *Cell) lastCol() int {
+	if c.colSpan > 1 {
+		return c.col + c.colSpan - 1
+	}
+	return c.col
+}
+
+func overlaps(from1, to1, from2, to2 int) bool {
+	return from1 <= to2 && from2 <= to1
+}
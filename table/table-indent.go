@@ -0,0 +1,54 @@
+package table
+
+import (
+	"sort"
+	"strings"
+)
+
+//tabWidth is the number of spaces that a tab counts for when measuring indentation
+const tabWidth = 4
+
+//applyIndentLevels sets the indent level of first-column cells from their leading spaces. Each distinct
+//indentation depth is a level, so that eg 2 and 4 spaces are levels 1 and 2 whatever the width of each step.
+//Header cells and empty cells are ignored
+func (t *Table) applyIndentLevels() {
+	var cells []*Cell
+	var depths []int
+	for _, row := range t.grid.rows {
+		if len(row.cells) == 0 {
+			continue
+		}
+		cell := row.cells[0]
+		if cell.header || cell.Merged() || strings.TrimSpace(cell.text) == "" {
+			continue
+		}
+		d := indentDepth(cell.text)
+		if d == 0 {
+			continue
+		}
+		cells = append(cells, cell)
+		if !containsInt(depths, d) {
+			depths = append(depths, d)
+		}
+	}
+	sort.Ints(depths)
+	for _, cell := range cells {
+		cell.indent = sort.SearchInts(depths, indentDepth(cell.text)) + 1
+	}
+}
+
+//indentDepth returns the width of the leading spaces and tabs of text
+func indentDepth(text string) int {
+	depth := 0
+	for _, r := range text {
+		switch r {
+		case ' ':
+			depth++
+		case '\t':
+			depth += tabWidth
+		default:
+			return depth
+		}
+	}
+	return depth
+}
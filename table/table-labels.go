@@ -0,0 +1,122 @@
+package table
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/drgo/rosewood/types"
+)
+
+//findLabel returns the number of the row whose first cell, or the first and last cols of the header cell,
+//that matches label. Header cells are those in rows listed in header row commands or, if there are none, in
+//the first row. Surrounding spaces are ignored but otherwise the text must match exactly. Cols are numbered
+//as in the merged grid, eg Adjusted in |Crude|Adjusted| is cols 4:5 if cols 2:3 and 4:5 of the row are merged
+func (t *Table) findLabel(kind, label string) (first, last int, err error) {
+	var found, lasts []int
+	switch kind {
+	case "row":
+		for i, row := range t.Contents.rows {
+			if len(row.cells) > 0 && strings.TrimSpace(row.cells[0].text) == label {
+				found, lasts = append(found, i+1), append(lasts, i+1)
+			}
+		}
+	case "col":
+		for _, r := range t.labelRows() {
+			firstCols, lastCols := t.gridCols(r)
+			for j, cell := range t.Contents.rows[r-1].cells {
+				if strings.TrimSpace(cell.text) == label && j < len(firstCols) && !containsInt(found, firstCols[j]) {
+					found, lasts = append(found, firstCols[j]), append(lasts, lastCols[j])
+				}
+			}
+		}
+	default:
+		panic("invalid kind in Table.findLabel()") //should never happen
+	}
+	switch len(found) {
+	case 1:
+		return found[0], lasts[0], nil
+	case 0:
+		if kind == "row" {
+			return 0, 0, fmt.Errorf("row label %q does not match the first cell of any row", label)
+		}
+		return 0, 0, fmt.Errorf("col label %q does not match any header cell", label)
+	}
+	list := make([]string, len(found))
+	for i, n := range found {
+		list[i] = strconv.Itoa(n)
+	}
+	return 0, 0, fmt.Errorf("%s label %q matches %ss %s; it must match exactly one %s", kind, label, kind,
+		strings.Join(list, ", "), kind)
+}
+
+//gridCols returns the first and last cols in the merged grid of each cell in row r of the source contents. As
+//in copyRowContents, source cells skip the cols hidden by horizontal merges, which are spanned by the cell to
+//their left. Only merge commands without labels are used because the grid cols are needed to resolve labels
+func (t *Table) gridCols(r int) (first, last []int) {
+	var hidden []int
+	for _, cmd := range t.CmdList {
+		if cmd.ID() != types.KwMerge || cmd.Span().HasLabels() {
+			continue
+		}
+		rList, err := cmd.Span().ExpandSpanToRanges()
+		if err != nil { //reported when the merge command is applied
+			continue
+		}
+		for _, ra := range rList {
+			if r < ra.TopLeft.Row || r > ra.BottomRight.Row {
+				continue
+			}
+			for c := ra.TopLeft.Col + 1; c <= ra.BottomRight.Col; c++ {
+				hidden = append(hidden, c)
+			}
+		}
+	}
+	count := len(t.Contents.rows[r-1].cells)
+	for c := 1; ; c++ {
+		switch {
+		case containsInt(hidden, c):
+			if len(last) > 0 {
+				last[len(last)-1] = c
+			}
+		case len(first) == count:
+			return first, last
+		default:
+			first, last = append(first, c), append(last, c)
+		}
+	}
+}
+
+//labelRows returns the numbers of the rows that hold col labels: the rows of the table head or the first row
+func (t *Table) labelRows() []int {
+	var rows []int
+	for _, cmd := range t.CmdList {
+		if cmd.ID() != types.KwHeader || cmd.SpanSegment("row") == nil || cmd.Span().HasLabels() {
+			continue
+		}
+		rList, err := cmd.Span().ExpandSpanToRanges()
+		if err != nil { //reported when the header command is applied
+			continue
+		}
+		for _, ra := range rList {
+			for r := ra.TopLeft.Row; r <= ra.BottomRight.Row && r <= t.Contents.RowCount(); r++ {
+				if r >= 1 && !containsInt(rows, r) {
+					rows = append(rows, r)
+				}
+			}
+		}
+	}
+	if len(rows) == 0 && t.Contents.RowCount() > 0 {
+		rows = append(rows, 1)
+	}
+	return rows
+}
+
+func containsInt(list []int, n int) bool {
+	for _, v := range list {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
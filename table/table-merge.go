@@ -1,13 +1,14 @@
 package table
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/drgo/rosewood/types"
 )
 
 //createMergedGridTable creates the underlying grid table and applies merging ranges to it
-func (t *Table) createMergedGridTable(mlist []types.Range) error {
+func (t *Table) createMergedGridTable(ctx context.Context, mlist []types.Range) error {
 	t.grid = NewBlankTableContents(t.Contents.RowCount(), t.Contents.MaxFieldCount())
 	//validate the ranges with respect to this table
 	if err := t.grid.ValidateRanges(mlist); err != nil {
@@ -41,6 +42,9 @@ func (t *Table) createMergedGridTable(mlist []types.Range) error {
 		//hide the other cells in the merge range. Error if a cell in the range is previously merged or spanned.
 		firstRowNum := -1
 		for r := mr.TopLeft.Row; r <= mr.BottomRight.Row; r++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 			for c := mr.TopLeft.Col; c <= mr.BottomRight.Col; c++ {
 				cell := t.grid.CellorPanic(r, c)
 				if cell == topleft {
@@ -71,6 +75,9 @@ func (t *Table) createMergedGridTable(mlist []types.Range) error {
 
 	//now fill each non-merged cell in the grid with the content of available cells in the raw contents
 	for r := 1; r <= t.Contents.RowCount(); r++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		t.Logf("row %d:\n", r) //DEBUG
 		if err := t.copyRowContents(r); err != nil {
 			return err
@@ -81,7 +88,7 @@ func (t *Table) createMergedGridTable(mlist []types.Range) error {
 }
 
 func (t *Table) copyRowContents(r int) error {
-	destRowLen := t.grid.Row(r).cellCount()
+	destRowLen := t.grid.Row(r).CellCount()
 	srcC := 1
 	for c := 1; c <= destRowLen; c++ {
 		destCell := t.grid.cell(r, c)
@@ -94,6 +101,7 @@ func (t *Table) copyRowContents(r int) error {
 		}
 		srcCell := t.Contents.cell(r, srcC)
 		destCell.text = srcCell.text
+		destCell.source = srcCell
 		t.Logf("     copied cell %d,%d to cell %d,%d\n", r, srcC, r, c) //DEBUG
 		srcC++
 	}
@@ -3,6 +3,8 @@
 package table
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"strings"
 
@@ -20,6 +22,10 @@ type Table struct {
 	Header     *types.Section
 	Footnotes  *types.Section
 	CmdList    []*types.Command
+	//true if the header cells need explicit associations with data cells, see hasComplexHeaders
+	complexHeaders bool
+	//IndentLevels turns the leading spaces of the first column into indent levels, see applyIndentLevels
+	IndentLevels bool
 }
 
 //NewTable returns a new empty Table
@@ -29,6 +35,22 @@ func NewTable(ux ui.UI) *Table {
 	}
 }
 
+//IndexOf returns the zero-based position of t in tables or -1 if not found
+func IndexOf(tables []*Table, t *Table) int {
+	for i, tt := range tables {
+		if tt == t {
+			return i
+		}
+	}
+	return -1
+}
+
+//ComplexHeaders returns true if the table has multi-level or merged header cells; in such tables,
+//each cell lists its header cells in Cell.HeaderCells
+func (t *Table) ComplexHeaders() bool {
+	return t.complexHeaders
+}
+
 //ProcessedTableContents returns a pointer to table contents after applying all commands
 func (t *Table) ProcessedTableContents() *TableContents {
 	return t.grid
@@ -57,7 +79,14 @@ func (t Table) String() string {
 
 //Run applies all commands to table contents. Must be called before rendering the table
 func (t *Table) Run() error {
-	t.fixMissingRangeValues()
+	return t.RunContext(context.Background())
+}
+
+//RunContext is like Run but stops and returns ctx.Err() if ctx is cancelled or times out
+func (t *Table) RunContext(ctx context.Context) error {
+	if err := t.fixMissingRangeValues(); err != nil {
+		return err
+	}
 	//create a list of merge ranges
 	rlist, err := types.GetAllRanges(t.CmdList, types.KwMerge)
 	if err != nil {
@@ -69,23 +98,45 @@ func (t *Table) Run() error {
 			t.Logf("%v\n", r)
 		}
 	}
-	if err = t.createMergedGridTable(rlist); err != nil {
+	if err = t.createMergedGridTable(ctx, rlist); err != nil {
 		return err
 	}
 	//create a list of style ranges
 	if rlist, err = types.GetAllRanges(t.CmdList, types.KwStyle); err != nil {
 		return err
 	}
-	return t.applyStyles(rlist)
+	if err = t.applyStyles(ctx, rlist); err != nil {
+		return err
+	}
+	//formats are applied after styles so that where clauses in style commands test the original numbers
+	if err = t.applyFormats(ctx); err != nil {
+		return err
+	}
+	if err = t.applyHeaders(ctx); err != nil {
+		return err
+	}
+	if t.IndentLevels {
+		t.applyIndentLevels()
+	}
+	//alignment follows headers, which are not aligned, and formats, which change the text
+	return t.applyAlignments(ctx)
 }
 
 //Render use a types.Renderer to render table contents and write them to io.Writer
 func (t *Table) Render(w io.Writer, hr Renderer) error {
+	return t.RenderContext(context.Background(), w, hr)
+}
+
+//RenderContext is like Render but stops and returns ctx.Err() if ctx is cancelled or times out
+func (t *Table) RenderContext(ctx context.Context, w io.Writer, hr Renderer) error {
 	t.Log("***starting rendering table")
 	if err := hr.StartTable(t); err != nil {
 		return err
 	}
 	for r, row := range t.grid.rows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if err := hr.StartRow(row); err != nil {
 			return err
 		}
@@ -104,27 +155,92 @@ func (t *Table) Render(w io.Writer, hr Renderer) error {
 	return hr.EndTable(t)
 }
 
-//fixMissingRangeValues fixes missing coordinates with reference to this table's dimensions
-func (t *Table) fixMissingRangeValues() (err error) {
-	for _, cmd := range t.CmdList {
-		if !types.IsTableCommand(cmd) {
-			continue
+//fixMissingRangeValues fixes missing, end-relative and label coordinates with reference to this table's
+//dimensions and contents. Spans with labels are resolved last because col labels are looked up in the
+//header rows
+func (t *Table) fixMissingRangeValues() error {
+	for _, labels := range []bool{false, true} {
+		for _, cmd := range t.CmdList {
+			if !types.IsTableCommand(cmd) || cmd.Span().HasLabels() != labels {
+				continue
+			}
+			if err := cmd.Span().ResolveLabels(t.findLabel); err != nil {
+				return fmt.Errorf("invalid span in %s: %s", cmd, err)
+			}
+			if err := cmd.Span().Normalize(t.Contents.RowCount(), t.Contents.MaxFieldCount()); err != nil {
+				return fmt.Errorf("invalid span in %s: %s", cmd, err)
+			}
+			if c := cmd.Condition(); c != nil {
+				if err := c.Normalize(t.Contents.RowCount(), t.Contents.MaxFieldCount()); err != nil {
+					return fmt.Errorf("invalid condition in %s: %s", cmd, err)
+				}
+			}
 		}
-		cmd.Span().Normalize(t.Contents.RowCount(), t.Contents.MaxFieldCount())
 	}
 	return nil
 }
 
-func (t *Table) applyStyles(rlist []types.Range) error {
+//matches returns true if the cell at row, col of the merged grid satisfies the where clause c
+func (t *Table) matches(c *types.Condition, row, col int) bool {
+	switch c.Subject {
+	case "row": //another cell in the same col
+		row = c.Index
+	case "col": //another cell in the same row
+		col = c.Index
+	}
+	cell := t.grid.Cell(row, col)
+	return cell != nil && c.Match(cell.text)
+}
+
+func (t *Table) applyStyles(ctx context.Context, rlist []types.Range) error {
 	if err := t.grid.ValidateRanges(rlist); err != nil {
 		return err
 	}
 	for _, mr := range rlist {
 		for i := mr.TopLeft.Row; i <= mr.BottomRight.Row; i++ {
+			if err := ctx.Err(); err != nil { //large ranges are applied cell by cell
+				return err
+			}
 			for j := mr.TopLeft.Col; j <= mr.BottomRight.Col; j++ {
+				if c := mr.Condition(); c != nil && !t.matches(c, i, j) {
+					continue
+				}
 				t.grid.CellorPanic(i, j).AddStyle(mr.Styles()...)
 			}
 		}
 	}
 	return nil
 }
+
+//applyFormats rewrites the text of the cells that each format command applies to, in the order of the commands
+func (t *Table) applyFormats(ctx context.Context) error {
+	for _, cmd := range t.CmdList {
+		if cmd.ID() != types.KwFormat {
+			continue
+		}
+		rlist, err := cmd.Span().ExpandSpanToRanges()
+		if err != nil {
+			return err
+		}
+		if err = t.grid.ValidateRanges(rlist); err != nil {
+			return err
+		}
+		done := make(map[types.Coordinates]bool) //ranges may overlap, eg row 1:2 union row 2:3
+		for _, r := range rlist {
+			for i := r.TopLeft.Row; i <= r.BottomRight.Row; i++ {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				for j := r.TopLeft.Col; j <= r.BottomRight.Col; j++ {
+					if c := cmd.Condition(); done[types.Coordinates{Row: i, Col: j}] || c != nil && !t.matches(c, i, j) {
+						continue
+					}
+					done[types.Coordinates{Row: i, Col: j}] = true
+					cell := t.grid.CellorPanic(i, j)
+					cell.text = cmd.NumberFormat().Apply(cell.text)
+				}
+			}
+		}
+	}
+	return nil
+}
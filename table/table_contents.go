@@ -31,9 +31,11 @@ func (t *TableContents) MaxFieldCount() int {
 	return t.maxFldCount
 }
 
-type cellFunc func(c *Cell) error
+//CellFunc is called for each cell visited by ForEachCell; returning an error stops the traversal
+type CellFunc func(c *Cell) error
 
-func (t *TableContents) forEachCell(f cellFunc) error {
+//ForEachCell calls f for each cell, row by row, including merged cells, and returns the first error returned by f
+func (t *TableContents) ForEachCell(f CellFunc) error {
 	for _, r := range t.rows {
 		for _, c := range r.cells {
 			if err := f(c); err != nil {
@@ -48,7 +50,7 @@ func (t *TableContents) isValidCoordinate(row, col int) bool {
 	if row < 1 || row > t.RowCount() {
 		return false
 	}
-	if col < 1 || col > t.Row(row).cellCount() {
+	if col < 1 || col > t.Row(row).CellCount() {
 		return false
 	}
 	return true
@@ -59,6 +61,22 @@ func (t *TableContents) Row(i int) *Row {
 	return t.rows[i-1]
 }
 
+//Rows returns the rows of the table in order. The returned slice is a copy but the rows are shared
+//and must be treated as read-only
+func (t *TableContents) Rows() []*Row {
+	rows := make([]*Row, len(t.rows))
+	copy(rows, t.rows)
+	return rows
+}
+
+//Cell returns the cell at row, col coordinates (1-based) or nil if the coordinates are not valid
+func (t *TableContents) Cell(row, col int) *Cell {
+	if !t.isValidCoordinate(row, col) {
+		return nil
+	}
+	return t.cell(row, col)
+}
+
 //RowCount returns the number of rows in a table
 func (t *TableContents) RowCount() int {
 	return len(t.rows)
@@ -104,14 +122,27 @@ func (t *TableContents) validateRange(ra types.Range) error {
 	return nil
 }
 
-//NewTableContents parses a Rosewood table contents
+//DefaultColumnSeparator separates cells in a table body unless configured otherwise
+const DefaultColumnSeparator = "|"
+
+//NewTableContents parses a Rosewood table contents using the default column separator
 func NewTableContents(text string) (*TableContents, error) {
+	return NewTableContentsWithSeparator(text, DefaultColumnSeparator)
+}
+
+//NewTableContentsWithSeparator parses a Rosewood table contents whose cells are terminated by sep,
+//which may be longer than one character. A separator preceded by a backslash (eg \|) is part of the cell text
+func NewTableContentsWithSeparator(text string, sep string) (*TableContents, error) {
 	var (
 		line, offset          int
 		fldCount, maxFldCount int
 		cells                 []*Cell
 		rows                  []*Row
+		escaped               bool //current cell contains an escaped separator
 	)
+	if sep == "" || strings.ContainsAny(sep, "\r\n\\") {
+		return nil, fmt.Errorf("invalid column separator %q", sep)
+	}
 	if strings.TrimSpace(text) == "" {
 		return nil, fmt.Errorf("empty table")
 	}
@@ -121,10 +152,8 @@ func NewTableContents(text string) (*TableContents, error) {
 	}
 	line = 1
 	for pos := 0; pos < len(text); pos++ {
-		switch text[pos] {
-		case '\r': //carriage return followed by linefeed as EOL sequence (Windows)
-
-		case '\n': //linefeed for Linux and MacOs as EOL marker
+		switch {
+		case text[pos] == '\n': //linefeed for Linux and MacOs as EOL marker; a preceding \r (Windows) is ignored
 			//TODO: add option to prevent cells with no field separator
 			// if fldCount == 0 {
 			// 	return nil, fmt.Errorf("row #%d has no cells", line)
@@ -137,11 +166,20 @@ func NewTableContents(text string) (*TableContents, error) {
 			offset = pos + 1 //offset is now just after the \n
 			fldCount = 0     //reset fldcount
 			cells = nil      //emtpy the cell slice
-		case '|':
+			escaped = false
+		case text[pos] == '\\' && strings.HasPrefix(text[pos+1:], sep): //escaped separator
+			escaped = true
+			pos += len(sep) //skip the separator
+		case strings.HasPrefix(text[pos:], sep):
 			fldCount++
-			cell := NewCell(text[offset:pos], line, fldCount) //text from last offset to just before the separator
-			cells = append(cells, cell)
-			offset = pos + 1 //offset is now just after the separator
+			cellText := text[offset:pos] //text from last offset to just before the separator
+			if escaped {
+				cellText = strings.Replace(cellText, "\\"+sep, sep, -1)
+				escaped = false
+			}
+			cells = append(cells, NewCell(cellText, line, fldCount))
+			offset = pos + len(sep) //offset is now just after the separator
+			pos = offset - 1
 		}
 	}
 	//TODO: fix situation where table has one field and no column separators
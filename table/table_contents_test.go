@@ -2,6 +2,8 @@
 package table
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/drgo/core/trace"
@@ -112,6 +114,122 @@ func TestParseTableData(t *testing.T) {
 	}
 }
 
+func TestParseTableDataWithSeparator(t *testing.T) {
+	tests := []struct {
+		name    string
+		sep     string
+		args    string
+		want    string
+		wantErr bool
+	}{
+		{"default separator", "|", "a|b|\nc|d|\n", "r1 c1: a|r1 c2: b|\nr2 c1: c|r2 c2: d|\n", false},
+		{"escaped pipe", "|", "a\\|b|c|\n", "r1 c1: a|b|r1 c2: c|\n", false},
+		{"two escaped pipes", "|", "a\\|b\\|c|d|\r\n", "r1 c1: a|b|c|r1 c2: d|\n", false},
+		{"other backslashes are kept", "|", "a\\*b|c|\n", "r1 c1: a\\*b|r1 c2: c|\n", false},
+		{"tab separator", "\t", "a|b\tc\t\n", "r1 c1: a|b|r1 c2: c|\n", false},
+		{"multi-character separator", "||", "a|b||c||\n", "r1 c1: a|b|r1 c2: c|\n", false},
+		{"escaped multi-character separator", "||", "a\\||b||c||\n", "r1 c1: a||b|r1 c2: c|\n", false},
+		{"empty separator", "", "a|b|\n", "", true},
+		{"separator with newline", "|\n", "a|b|\n", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTableContentsWithSeparator(tt.args, tt.sep)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewTableContentsWithSeparator() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("NewTableContentsWithSeparator() = [%v], want [%v]", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTableContentsFromCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		comma   rune
+		args    string
+		want    string
+		wantErr bool
+	}{
+		{"csv", ',', "a,b\nc,d\n", "r1 c1: a|r1 c2: b|\nr2 c1: c|r2 c2: d|\n", false},
+		{"quoted fields", ',', `"a,b","say ""hi""",c` + "\n", `r1 c1: a,b|r1 c2: say "hi"|r1 c3: c|` + "\n", false},
+		{"embedded newline", ',', "\"a\r\nb\",c\r\n", "r1 c1: a\nb|r1 c2: c|\n", false},
+		{"ragged rows", ',', "a,b,c\nd\n", "r1 c1: a|r1 c2: b|r1 c3: c|\nr2 c1: d|\n", false},
+		{"byte order mark", ',', "\xef\xbb\xbfa,b\n", "r1 c1: a|r1 c2: b|\n", false},
+		{"tsv", '\t', "a|b\tc,d\n", "r1 c1: a|b|r1 c2: c,d|\n", false},
+		{"unterminated quote", ',', "\"a,b\n", "", true},
+		{"empty input", ',', "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTableContentsFromCSV(strings.NewReader(tt.args), tt.comma)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewTableContentsFromCSV() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("NewTableContentsFromCSV() = [%v], want [%v]", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTableContentsFromFixedWidth(t *testing.T) {
+	const listing = "" +
+		"Variable       Mean    SD\n" +
+		"------------------------------\n" +
+		"Age at entry   54.2    9.1\n" +
+		"  Female       53.9   10.0\n" +
+		"\n" +
+		"Weight\n"
+	tests := []struct {
+		name       string
+		args       string
+		opts       FixedWidthOptions
+		wantStarts []int
+		want       string
+		wantErr    bool
+	}{
+		{"inferred columns", listing, FixedWidthOptions{}, []int{1, 16, 23},
+			"r1 c1: Variable|r1 c2: Mean|r1 c3: SD|\n" +
+				"r2 c1: Age at entry|r2 c2: 54.2|r2 c3: 9.1|\n" +
+				"r3 c1:   Female|r3 c2: 53.9|r3 c3: 10.0|\n" +
+				"r4 c1: Weight|\n", false},
+		{"overridden columns", listing, FixedWidthOptions{ColumnStarts: []int{16}}, []int{1, 16, 23},
+			"r1 c1: Variable|r1 c2: Mean    SD|\n" +
+				"r2 c1: Age at entry|r2 c2: 54.2    9.1|\n" +
+				"r3 c1:   Female|r3 c2: 53.9   10.0|\n" +
+				"r4 c1: Weight|\n", false},
+		{"single spaces do not split columns", "a b  c\nd e  f\n", FixedWidthOptions{}, []int{1, 6},
+			"r1 c1: a b|r1 c2: c|\nr2 c1: d e|r2 c2: f|\n", false},
+		{"min gap of 1", "a b  c\nd e  f\n", FixedWidthOptions{MinGap: 1}, []int{1, 3, 6},
+			"r1 c1: a|r1 c2: b|r1 c3: c|\nr2 c1: d|r2 c2: e|r2 c3: f|\n", false},
+		{"tabs", "a\tb\nc\td\n", FixedWidthOptions{}, []int{1, 9},
+			"r1 c1: a|r1 c2: b|\nr2 c1: c|r2 c2: d|\n", false},
+		{"invalid column start", listing, FixedWidthOptions{ColumnStarts: []int{0}}, []int{1, 16, 23}, "", true},
+		{"duplicate column start", listing, FixedWidthOptions{ColumnStarts: []int{16, 16}}, []int{1, 16, 23}, "", true},
+		{"empty input", "\n---\n", FixedWidthOptions{}, []int{1}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InferColumnStarts(tt.args, tt.opts); !reflect.DeepEqual(got, tt.wantStarts) {
+				t.Errorf("InferColumnStarts() = %v, want %v", got, tt.wantStarts)
+			}
+			got, err := NewTableContentsFromFixedWidth(tt.args, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewTableContentsFromFixedWidth() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("NewTableContentsFromFixedWidth() = [%v], want [%v]", got, tt.want)
+			}
+		})
+	}
+}
+
 // func Test_tableContents_ValidateRange(t *testing.T) {
 // 	tests := []struct {
 // 		name    string
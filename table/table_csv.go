@@ -0,0 +1,48 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package table
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+//NewTableContentsFromCSV parses RFC 4180 delimited data (eg csv with comma=',' or tsv with comma='\t')
+//into table contents. Fields may be quoted and may contain embedded separators, quotes and newlines
+func NewTableContentsFromCSV(r io.Reader, comma rune) (*TableContents, error) {
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(3); err == nil && string(bom) == "\xef\xbb\xbf" { //skip the byte order mark added by Excel
+		br.Discard(3)
+	}
+	cr := csv.NewReader(br)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1 //rows may have different numbers of fields like in Rosewood tables
+	var (
+		rows        []*Row
+		maxFldCount int
+	)
+	for line := 1; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid delimited data: %s", err)
+		}
+		cells := make([]*Cell, len(record))
+		for i, field := range record {
+			cells[i] = NewCell(field, line, i+1)
+		}
+		if len(cells) > maxFldCount {
+			maxFldCount = len(cells)
+		}
+		rows = append(rows, &Row{cells: cells})
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("invalid data table, row count is 0")
+	}
+	return &TableContents{rows: rows,
+		maxFldCount: maxFldCount}, nil
+}
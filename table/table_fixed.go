@@ -0,0 +1,161 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package table
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//FixedWidthOptions controls importing space-aligned text such as SAS and Stata listings
+type FixedWidthOptions struct {
+	//ColumnStarts lists the character positions (1-based, as shown by most editors) where columns start.
+	//If empty, they are inferred from the text
+	ColumnStarts []int
+	//MinGap is the minimum number of blank positions that separate inferred columns; defaults to 2 so that
+	//single spaces within labels do not split them
+	MinGap int
+	//TabWidth is used to expand tabs; defaults to 8
+	TabWidth int
+}
+
+//NewTableContentsFromFixedWidth parses space-aligned text into table contents. Blank lines and rule lines
+//(made of -, = and +) are skipped. Leading spaces in the first column are kept because they usually
+//indicate indentation; other cells are trimmed
+func NewTableContentsFromFixedWidth(text string, opts FixedWidthOptions) (*TableContents, error) {
+	lines := fixedWidthLines(text, opts.TabWidth)
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("empty table")
+	}
+	starts := opts.ColumnStarts
+	if len(starts) == 0 {
+		starts = inferColumnStarts(lines, opts.MinGap)
+	} else {
+		var err error
+		if starts, err = normalizeColumnStarts(starts); err != nil {
+			return nil, err
+		}
+	}
+	var (
+		rows        []*Row
+		maxFldCount int
+	)
+	for i, line := range lines {
+		var cells []*Cell
+		for j, start := range starts {
+			if start >= len(line) {
+				break
+			}
+			end := len(line)
+			if j+1 < len(starts) && starts[j+1] < end {
+				end = starts[j+1]
+			}
+			text := strings.TrimRight(string(line[start:end]), " ")
+			if j > 0 {
+				text = strings.TrimLeft(text, " ")
+			}
+			cells = append(cells, NewCell(text, i+1, j+1))
+		}
+		for len(cells) > 1 && strings.TrimSpace(cells[len(cells)-1].text) == "" { //drop trailing empty cells
+			cells = cells[:len(cells)-1]
+		}
+		if len(cells) > maxFldCount {
+			maxFldCount = len(cells)
+		}
+		rows = append(rows, &Row{cells: cells})
+	}
+	return &TableContents{rows: rows,
+		maxFldCount: maxFldCount}, nil
+}
+
+//InferColumnStarts returns the character positions (1-based) where columns start in space-aligned text.
+//A column starts after a run of at least opts.MinGap positions that are blank in every line.
+//Useful to review the detected columns before overriding them in opts.ColumnStarts
+func InferColumnStarts(text string, opts FixedWidthOptions) []int {
+	starts := inferColumnStarts(fixedWidthLines(text, opts.TabWidth), opts.MinGap)
+	for i := range starts {
+		starts[i]++
+	}
+	return starts
+}
+
+//inferColumnStarts returns zero-based column start positions
+func inferColumnStarts(lines [][]rune, minGap int) []int {
+	if minGap < 1 {
+		minGap = 2
+	}
+	width := 0
+	for _, line := range lines {
+		if len(line) > width {
+			width = len(line)
+		}
+	}
+	used := make([]bool, width) //true if any line has a non-blank char at this position
+	for _, line := range lines {
+		for i, r := range line {
+			if r != ' ' {
+				used[i] = true
+			}
+		}
+	}
+	starts := []int{0} //the first column always starts at the beginning of the line
+	gap := 0
+	for i := 0; i < width; i++ {
+		if !used[i] {
+			gap++
+			continue
+		}
+		if gap >= minGap && i > gap { //ignore blanks at the start of all lines
+			starts = append(starts, i)
+		}
+		gap = 0
+	}
+	return starts
+}
+
+//normalizeColumnStarts converts user-supplied 1-based positions to sorted zero-based ones
+func normalizeColumnStarts(positions []int) ([]int, error) {
+	starts := make([]int, 0, len(positions)+1)
+	for _, p := range positions {
+		if p < 1 {
+			return nil, fmt.Errorf("invalid column start %d: positions start at 1", p)
+		}
+		starts = append(starts, p-1)
+	}
+	sort.Ints(starts)
+	if starts[0] != 0 { //the first column always starts at the beginning of the line
+		starts = append([]int{0}, starts...)
+	}
+	for i := 1; i < len(starts); i++ {
+		if starts[i] == starts[i-1] {
+			return nil, fmt.Errorf("duplicate column start %d", starts[i]+1)
+		}
+	}
+	return starts, nil
+}
+
+//fixedWidthLines splits text into lines of runes with tabs expanded, skipping blank and rule lines
+func fixedWidthLines(text string, tabWidth int) [][]rune {
+	if tabWidth < 1 {
+		tabWidth = 8
+	}
+	var lines [][]rune
+	for _, s := range strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n") {
+		if strings.Trim(s, " \t-=+") == "" { //blank or rule line eg -------
+			continue
+		}
+		var line []rune
+		for _, r := range s {
+			if r == '\t' {
+				for n := tabWidth - len(line)%tabWidth; n > 0; n-- {
+					line = append(line, ' ')
+				}
+				continue
+			}
+			line = append(line, r)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
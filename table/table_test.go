@@ -0,0 +1,514 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package table_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/drgo/rosewood"
+	"github.com/drgo/rosewood/table"
+)
+
+const mergedTab = `+++
+Table 1
++++
+Brand|Some numbers|
+|Models|Price|
+AMC|3|4,215.67|
++++
++++
+merge row 1:2 col 1
+merge row 1 col 2:3
+style row 3 col 3 right
++++
+`
+
+func TestTraversal(t *testing.T) {
+	ri := rosewood.NewInterpreter(rosewood.DefaultJob(rosewood.DefaultSettings()))
+	file, err := ri.Parse(strings.NewReader(mergedTab), "traversal")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	tab := file.Tables()[0]
+	if err := tab.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	//describe returns a line per cell listing its coordinates, state, spans, styles and source
+	describe := func(contents *table.TableContents) string {
+		var b strings.Builder
+		contents.ForEachCell(func(c *table.Cell) error {
+			src := "-"
+			if c.Source() != nil {
+				src = fmt.Sprintf("%d,%d", c.Source().Row(), c.Source().Col())
+			}
+			fmt.Fprintf(&b, "%d,%d %q state=%d span=%dx%d styles=%v src=%s\n", c.Row(), c.Col(), c.Text(),
+				c.State(), c.RowSpan(), c.ColSpan(), c.Styles(), src)
+			return nil
+		})
+		return b.String()
+	}
+	tests := []struct {
+		name     string
+		contents *table.TableContents
+		want     string
+	}{
+		{"source contents", tab.Contents, `1,1 "Brand" state=0 span=0x0 styles=[] src=-
+1,2 "Some numbers" state=0 span=0x0 styles=[] src=-
+2,1 "" state=0 span=0x0 styles=[] src=-
+2,2 "Models" state=0 span=0x0 styles=[] src=-
+2,3 "Price" state=0 span=0x0 styles=[] src=-
+3,1 "AMC" state=0 span=0x0 styles=[] src=-
+3,2 "3" state=0 span=0x0 styles=[] src=-
+3,3 "4,215.67" state=0 span=0x0 styles=[] src=-
+`},
+		{"processed contents", tab.ProcessedTableContents(), `1,1 "Brand" state=1 span=2x1 styles=[] src=1,1
+1,2 "Some numbers" state=1 span=1x2 styles=[] src=1,2
+1,3 "" state=2 span=0x0 styles=[] src=-
+2,1 "" state=3 span=0x0 styles=[] src=2,1
+2,2 "Models" state=0 span=0x0 styles=[] src=2,2
+2,3 "Price" state=0 span=0x0 styles=[] src=2,3
+3,1 "AMC" state=0 span=0x0 styles=[] src=3,1
+3,2 "3" state=0 span=0x0 styles=[] src=3,2
+3,3 "4,215.67" state=0 span=0x0 styles=[right] src=3,3
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describe(tt.contents); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+			rows := tt.contents.Rows()
+			if len(rows) != tt.contents.RowCount() {
+				t.Errorf("Rows() returned %d rows, want %d", len(rows), tt.contents.RowCount())
+			}
+			for i, row := range rows {
+				for j, c := range row.Cells() {
+					if c != tt.contents.Cell(i+1, j+1) || c != row.Cell(j+1) {
+						t.Errorf("cell %d,%d differs between Rows() and Cell()", i+1, j+1)
+					}
+					if styles := c.Styles(); len(styles) > 0 {
+						styles[0] = "changed"
+						if c.Styles()[0] == "changed" {
+							t.Errorf("Styles() of cell %d,%d returned the internal style list", i+1, j+1)
+						}
+					}
+				}
+				if row.Cell(row.CellCount()+1) != nil {
+					t.Errorf("Row.Cell() returned a cell beyond the end of row %d", i+1)
+				}
+			}
+			if tt.contents.Cell(0, 1) != nil || tt.contents.Cell(tt.contents.RowCount()+1, 1) != nil {
+				t.Errorf("Cell() returned a cell for invalid coordinates")
+			}
+		})
+	}
+}
+
+//runTable parses src using settings and runs the commands of its first table
+func runTable(t *testing.T, settings *rosewood.Settings, src string) (*table.Table, error) {
+	t.Helper()
+	ri := rosewood.NewInterpreter(rosewood.DefaultJob(settings))
+	file, err := ri.Parse(strings.NewReader(src), t.Name())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	tab := file.Tables()[0]
+	return tab, tab.Run()
+}
+
+//runCmds runs the first table of src with {cmds} replaced by cmds using the default settings
+func runCmds(t *testing.T, src, cmds string) (*table.Table, error) {
+	t.Helper()
+	return runTable(t, rosewood.DefaultSettings(), strings.Replace(src, "{cmds}", cmds, 1))
+}
+
+//describeRows returns a line per row that lists the description of each of its cells separated by sep
+func describeRows(rows []*table.Row, sep string, describe func(c *table.Cell) string) string {
+	var b strings.Builder
+	for _, row := range rows {
+		for i, c := range row.Cells() {
+			if i > 0 {
+				b.WriteString(sep)
+			}
+			b.WriteString(describe(c))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+//cellStyles describes a cell by its styles, eg [bold italic]
+func cellStyles(c *table.Cell) string {
+	return fmt.Sprint(c.Styles())
+}
+
+func TestEndRelativeAddressing(t *testing.T) {
+	const src = `+++
+Table 1
++++
+Group|n|%|
+A|1|10|
+B|2|20|
+Total|3|30|
++++
++++
+{cmds}
++++
+`
+	tests := []struct {
+		name    string
+		cmds    string
+		want    string //styles or state of each cell
+		wantErr bool
+	}{
+		{"last row", "style row -1 total", `[] [] []
+[] [] []
+[] [] []
+[total] [total] [total]
+`, false},
+		{"max-k range", "style row max-2:max-1 col max bold", `[] [] []
+[] [] [bold]
+[] [] [bold]
+[] [] []
+`, false},
+		{"step to max", "style row 1:2:max col 1 italic", `[italic] [] []
+[] [] []
+[italic] [] []
+[] [] []
+`, false},
+		{"merge relative", "merge row max-1:max col -3:-2", `[] [] []
+[] [] []
+[] merged []
+merged merged []
+`, false},
+		{"outside table", "style row -5 bold", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tab, err := runCmds(t, src, tt.cmds)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := describeRows(tab.ProcessedTableContents().Rows(), " ", func(c *table.Cell) string {
+				if c.Merged() {
+					return "merged"
+				}
+				return cellStyles(c)
+			})
+			if got != tt.want {
+				t.Errorf("Run() =\n%s, want\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabelAddressing(t *testing.T) {
+	const src = `+++
+Table 1
++++
+Outcome|Crude OR (95% CI)|Adjusted OR (95% CI)||
+|Estimate|Estimate|n|
+Stroke|1.2|1.1|10|
+ Total |2.0|1.9|30|
+Total|3|3|3|
++++
++++
+{cmds}
++++
+`
+	tests := []struct {
+		name    string
+		cmds    string
+		want    string //styles of the cells of rows 3 and 4
+		wantErr string
+	}{
+		{"col label", `style col "Crude OR (95% CI)" right`, "[] [right] [] []\n[] [right] [] []\n", ""},
+		{"row and col labels", `style row "Stroke" col "Adjusted OR (95% CI)" bold`, "[] [] [bold] []\n[] [] [] []\n", ""},
+		{"col label in header rows", "header row 1:2\n" + `style col "n" italic`, "[] [] [] [italic]\n[] [] [] [italic]\n", ""},
+		{"not in header rows", `style col "n" italic`, "", `col label "n" does not match any header cell`},
+		{"no match", `style col "OR" right`, "", `col label "OR" does not match any header cell`},
+		{"several cols", "header row 1:2\n" + `style col "Estimate" italic`, "",
+			`col label "Estimate" matches cols 2, 3; it must match exactly one col`},
+		{"several rows", `style row "Total" bold`, "", `row label "Total" matches rows 4, 5; it must match exactly one row`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tab, err := runCmds(t, src, tt.cmds)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Run() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if got := describeRows(tab.ProcessedTableContents().Rows()[2:4], " ", cellStyles); got != tt.want {
+				t.Errorf("Run() =\n%s, want\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabelsInMergedHeader(t *testing.T) {
+	const src = `+++
+Table 1
++++
+|Crude|Adjusted|
+|OR|p|OR|p|
+Stroke|1.2|0.2|1.1|0.3|
++++
++++
+merge row 1 col 2:3
+merge row 1 col 4:5
+header row 1:2
+{cmds}
++++
+`
+	tests := []struct {
+		name string
+		cmds string
+		want string //styles of the cells of row 3
+	}{
+		{"after a merge", `style col "Adjusted" hl`, "[] [] [] [hl] [hl]"},
+		{"whole merged span", `style col "Crude" hl`, "[] [hl] [hl] [] []"},
+		{"vertical merge", "merge row 1:2 col 1\n" + `style col "Adjusted" hl`, "[] [] [] [hl] [hl]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tab, err := runCmds(t, src, tt.cmds)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if got := describeRows(tab.ProcessedTableContents().Rows()[2:3], " ", cellStyles); got != tt.want+"\n" {
+				t.Errorf("Run() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWhereClause(t *testing.T) {
+	const src = `+++
+Table 1
++++
+Outcome|OR|95% CI|p|
+Stroke|1.20|0.9-1.5|0.21|
+MI|0.04|0.01-0.2|<0.001|
+Death|2.50|1.1-5.3|0.03|
++++
++++
+{cmds}
++++
+`
+	tests := []struct {
+		name string
+		cmds string
+		want string //styles of each cell
+	}{
+		{"other col", "style row 2:max where col 4 < 0.05 significant", `[] [] [] []
+[] [] [] []
+[significant] [significant] [significant] [significant]
+[significant] [significant] [significant] [significant]
+`},
+		{"own text", `style col 2:max where text ~ "^0\.0" highlight`, `[] [] [] []
+[] [] [] []
+[] [highlight] [highlight] []
+[] [] [] [highlight]
+`},
+		{"other row", "style col 2:max where row 1 = p bold", `[] [] [] [bold]
+[] [] [] [bold]
+[] [] [] [bold]
+[] [] [] [bold]
+`},
+		{"relative col", "style row 2:max col 1 where col -1 >= 0.05 ns", `[] [] [] []
+[ns] [] [] []
+[] [] [] []
+[] [] [] []
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tab, err := runCmds(t, src, tt.cmds)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if got := describeRows(tab.ProcessedTableContents().Rows(), " ", cellStyles); got != tt.want {
+				t.Errorf("Run() =\n%s, want\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpanSetOperations(t *testing.T) {
+	const src = `+++
+Table 1
++++
+a|b|c|
+d|e|f|
+g|h|i|
+j|k|l|
++++
++++
+{cmds}
++++
+`
+	tests := []struct {
+		name    string
+		cmds    string
+		want    string //styles of each cell
+		wantErr bool
+	}{
+		{"except rows", "style row 2:max except row 3 col 2:max x", `[] [] []
+[] [x] [x]
+[] [] []
+[] [x] [x]
+`, false},
+		{"except in both dimensions", "style row 1:max except row -1 col 1:max except col 2 x", `[x] [] [x]
+[x] [] [x]
+[x] [] [x]
+[] [] []
+`, false},
+		{"except list and step", "style row 1:4 except row 1,3 col 1:1:3 except col 1:2:3 x", `[] [] []
+[] [x] []
+[] [] []
+[] [x] []
+`, false},
+		{"except label", `style row 1:max except row "g" col 1 x`, `[x] [] []
+[x] [] []
+[] [] []
+[x] [] []
+`, false},
+		{"union", "style row 1 col 1 union row 3:4 col 3 union row 1 col 1:2 x", `[x] [x] []
+[] [] []
+[] [] [x]
+[] [] [x]
+`, false},
+		{"range and list", "style row 1:2, 4 col 1 x", `[x] [] []
+[x] [] []
+[] [] []
+[x] [] []
+`, false},
+		{"except all rows", "style row 2 except row 2 x", "", true},
+		{"except outside the table", "style row 1:max except row -5 x", "", true},
+		{"union outside the table", "style row 1 union row 5 x", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tab, err := runCmds(t, src, tt.cmds)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := describeRows(tab.ProcessedTableContents().Rows(), " ", cellStyles); got != tt.want {
+				t.Errorf("Run() =\n%s, want\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatCommand(t *testing.T) {
+	const src = `+++
+Table 1
++++
+Outcome|N|Rate|p|
+Stroke|12345|0.1234|0.21|
+MI|987|0.07|0.0004|
+Death|NA|0.5|0.04567|
++++
++++
+{cmds}
++++
+`
+	tests := []struct {
+		name string
+		cmds string
+		want string //text of each cell
+	}{
+		{"thousands and percent", `format col 2 thousands ","
+format col 3 percent decimals 1`, `Outcome|N|Rate|p
+Stroke|12,345|12.3%|0.21
+MI|987|7.0%|0.0004
+Death|NA|50.0%|0.04567
+`},
+		{"pvalue", `format col 4 pvalue "<0.001"`, `Outcome|N|Rate|p
+Stroke|12345|0.1234|0.210
+MI|987|0.07|<0.001
+Death|NA|0.5|0.046
+`},
+		{"where and union", `format row 2:max col 3 union row 4 col 4 where col 1 != "MI" decimals 2`, `Outcome|N|Rate|p
+Stroke|12345|0.12|0.21
+MI|987|0.07|0.0004
+Death|NA|0.50|0.05
+`},
+		{"styles test the original text", `style row 2:max col 4 where text < 0.05 significant
+format col 4 decimals 1`, `Outcome|N|Rate|p
+Stroke|12345|0.1234|0.2
+MI|987|0.07|0.0{significant}
+Death|NA|0.5|0.0{significant}
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tab, err := runCmds(t, src, tt.cmds)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			got := describeRows(tab.ProcessedTableContents().Rows(), "|", func(c *table.Cell) string {
+				if len(c.Styles()) > 0 {
+					return strings.TrimSpace(c.Text()) + cellStyles(c)
+				}
+				return strings.TrimSpace(c.Text())
+			})
+			want := strings.NewReplacer("{", "[", "}", "]").Replace(tt.want)
+			if got != want {
+				t.Errorf("Run() =\n%s, want\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestIndentLevels(t *testing.T) {
+	const src = `+++
+Table 1
++++
+  Outcome|OR|
+Ever-use of any statin|
+  No|ref|
+  Yes|0.96|
+Use of statin by potency|
+    Low|1.00|
+	High|0.95|
+      |1|
++++
++++
+header row 1
+merge row 2 col 1:2
++++
+`
+	for _, enabled := range []bool{false, true} {
+		settings := rosewood.DefaultSettings()
+		settings.IndentLevels = enabled
+		tab, err := runTable(t, settings, src)
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		var got []int
+		for _, row := range tab.ProcessedTableContents().Rows() {
+			got = append(got, row.Cells()[0].Indent())
+		}
+		want := "[0 0 0 0 0 0 0 0]"
+		if enabled { //header and empty cells are not indented; a tab counts as 4 spaces
+			want = "[0 0 1 1 0 2 2 0]"
+		}
+		if fmt.Sprint(got) != want {
+			t.Errorf("IndentLevels=%v: Indent() of first-column cells = %v, want %s", enabled, got, want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//Alignment holds the options of an align command, eg align col 2:3 decimal or align col 2 on "("
+type Alignment struct {
+	Decimal bool   //align numbers on their decimal point
+	On      string //align on the first occurrence of this text
+}
+
+//NewAlignment returns an Alignment from the arguments of an align command: decimal or on followed by a quoted char
+func NewAlignment(args []string) (*Alignment, error) {
+	switch {
+	case len(args) == 1 && args[0] == "decimal":
+		return &Alignment{Decimal: true}, nil
+	case len(args) == 2 && args[0] == "on":
+		on, err := strconv.Unquote(args[1])
+		if err != nil || on == "" || strings.TrimSpace(on) != on {
+			return nil, fmt.Errorf("expected the text to align on in quotes, eg on \"(\", found %s", args[1])
+		}
+		return &Alignment{On: on}, nil
+	}
+	return nil, fmt.Errorf(`expected decimal or on followed by a quoted char, eg align col 2 on "(", found %s`, rwArgs(args))
+}
+
+func (a *Alignment) String() string {
+	if a.Decimal {
+		return "decimal"
+	}
+	return "on " + strconv.Quote(a.On)
+}
+
+//integerPart matches the integer part of a number at the start of text, eg 1,234 in 1,234.5 (1.1-2.2)
+var integerPart = regexp.MustCompile(`^[<>]?\s*[-+]?(\d[\d,]*)?`)
+
+//Split returns the text before and after the alignment point of text. For decimal alignment, the point follows
+//the integer part of the number that text starts with; otherwise, it is the start of the first occurrence of On
+//or the end of text if it does not contain On (eg 1.00 in a col of estimates aligned on "("). It returns ok=false
+//if text is empty or, for decimal alignment, does not start with a number
+func (a *Alignment) Split(text string) (before, after string, ok bool) {
+	if !a.Decimal {
+		i := strings.Index(text, a.On)
+		if i < 0 {
+			i = len(text)
+		}
+		return text[:i], text[i:], text != ""
+	}
+	m := integerPart.FindStringSubmatch(text)
+	i := len(m[0])
+	if m[1] == "" { //eg .5, but not NA
+		if i+1 >= len(text) || text[i] != '.' || text[i+1] < '0' || text[i+1] > '9' {
+			return "", "", false
+		}
+	}
+	return text[:i], text[i:], true
+}
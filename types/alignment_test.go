@@ -0,0 +1,33 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package types
+
+import "testing"
+
+func TestAlignment_Split(t *testing.T) {
+	decimal, on := &Alignment{Decimal: true}, &Alignment{On: "("}
+	tests := []struct {
+		a             *Alignment
+		text          string
+		before, after string
+		ok            bool
+	}{
+		{decimal, "12.34", "12", ".34", true},
+		{decimal, "-1,234.5 (1.1-2.2)", "-1,234", ".5 (1.1-2.2)", true},
+		{decimal, "7", "7", "", true},
+		{decimal, "<0.001", "<0", ".001", true},
+		{decimal, ".25", "", ".25", true},
+		{decimal, "NA", "", "", false},
+		{decimal, ".", "", "", false},
+		{on, "1.20 (0.90-1.50)", "1.20 ", "(0.90-1.50)", true},
+		{on, "(ref)", "", "(ref)", true},
+		{on, "1.00", "1.00", "", true},
+		{on, "", "", "", false},
+	}
+	for _, tt := range tests {
+		before, after, ok := tt.a.Split(tt.text)
+		if before != tt.before || after != tt.after || ok != tt.ok {
+			t.Errorf("%s Split(%q) = %q, %q, %v, want %q, %q, %v", tt.a, tt.text, before, after, ok, tt.before, tt.after, tt.ok)
+		}
+	}
+}
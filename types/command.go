@@ -19,11 +19,16 @@ func (args rwArgs) String() string {
 
 //Command is the AST for a Rosewood command.
 type Command struct {
-	token        RwKeyWord      //command token
-	name         string         //command name
-	cellSpan     *Span          //holds the complete valid description of the span that the command applies to
-	spanSegments []*SpanSegment //row and/or col table spanSegments that the command applies to.
-	args         rwArgs         //additional arguments passed to the command
+	token        RwKeyWord        //command token
+	name         string           //command name
+	cellSpan     *Span            //holds the complete valid description of the span that the command applies to
+	spanSegments []*SpanSegment   //row and/or col table spanSegments that the command applies to.
+	unions       [][]*SpanSegment //other blocks of row and/or col spanSegments added using union
+	args         rwArgs           //additional arguments passed to the command
+	condition    *Condition       //where clause of a style or format command, if any
+	format       *NumberFormat    //options of a format command
+	alignment    *Alignment       //options of an align command
+	line         int              //line number in the source file; 0 if unknown
 }
 
 //NewCommand return an empty RwCommand
@@ -36,8 +41,16 @@ func (c *Command) String() string {
 	buf := &bytes.Buffer{}
 	fmt.Fprintf(buf, "%s ", c.name)
 	for _, s := range c.spanSegments {
-		fmt.Fprintf(buf, s.String())
-		fmt.Fprintf(buf, " ")
+		buf.WriteString(s.String() + " ")
+	}
+	for _, block := range c.unions {
+		buf.WriteString("union ")
+		for _, s := range block {
+			buf.WriteString(s.String() + " ")
+		}
+	}
+	if c.condition != nil {
+		buf.WriteString(c.condition.String() + " ")
 	}
 	if len(c.args) > 0 {
 		fmt.Fprintf(buf, "%s", c.args)
@@ -50,6 +63,16 @@ func (c *Command) ID() RwKeyWord {
 	return c.token
 }
 
+//Line returns the number of the source line that holds the command or 0 if unknown
+func (c *Command) Line() int {
+	return c.line
+}
+
+//SetLine sets the number of the source line that holds the command
+func (c *Command) SetLine(line int) {
+	c.line = line
+}
+
 //Span returns a span struct describing the span of cells the command applies to
 func (c *Command) Span() *Span {
 	return c.cellSpan
@@ -62,12 +85,38 @@ func (c *Command) AddSpanSegment(segment *SpanSegment) error {
 	return nil
 }
 
+//AddUnion adds a block of row and/or col SpanSegments whose cells are added to the cells the command applies to
+func (c *Command) AddUnion(block []*SpanSegment) error {
+	c.unions = append(c.unions, block)
+	return nil
+}
+
 //AddArg adds one or more arguments to the command
 func (c *Command) AddArg(arg ...string) error {
 	c.args = append(c.args, arg...)
 	return nil
 }
 
+//Condition returns the where clause of the command or nil if none
+func (c *Command) Condition() *Condition {
+	return c.condition
+}
+
+//SetCondition sets the where clause of the command
+func (c *Command) SetCondition(condition *Condition) {
+	c.condition = condition
+}
+
+//NumberFormat returns the options of a format command or nil for other commands
+func (c *Command) NumberFormat() *NumberFormat {
+	return c.format
+}
+
+//Alignment returns the options of an align command or nil for other commands
+func (c *Command) Alignment() *Alignment {
+	return c.alignment
+}
+
 //SpanSegment returns a SpanSegment corresponding to the specified kind: row or col
 func (c *Command) SpanSegment(kind string) *SpanSegment {
 	for _, segment := range c.spanSegments {
@@ -98,7 +147,13 @@ func (c *Command) Arg(index int) string {
 //TODO:
 func (c *Command) Finalize() error {
 	checkCmd := func() error {
+		if c.condition != nil && c.token != KwStyle && c.token != KwFormat {
+			return fmt.Errorf("where clauses are only allowed in style and format commands")
+		}
 		c.cellSpan = NewSpanFromSpanSegments(c.spanSegments)
+		for _, block := range c.unions {
+			c.cellSpan.union = append(c.cellSpan.union, NewSpanFromSpanSegments(block))
+		}
 		if err := c.cellSpan.Validate(); err != nil {
 			return err
 		}
@@ -109,10 +164,31 @@ func (c *Command) Finalize() error {
 		return checkCmd()
 	case KwStyle:
 		return checkCmd()
+	case KwHeader:
+		if len(c.args) > 0 {
+			return fmt.Errorf("header command does not take arguments, found %s", c.args)
+		}
+		return checkCmd()
+	case KwFormat:
+		var err error
+		if c.format, err = NewNumberFormat(c.args); err != nil {
+			return err
+		}
+		return checkCmd()
+	case KwAlign:
+		var err error
+		if c.alignment, err = NewAlignment(c.args); err != nil {
+			return err
+		}
+		return checkCmd()
 	case KwSet:
 		if len(c.args) != 2 {
 			return fmt.Errorf("expected 2 arguments, found %d arguments", len(c.args))
 		}
+	case KwUse:
+		if len(c.args) != 1 {
+			return fmt.Errorf("expected 1 argument, found %d arguments", len(c.args))
+		}
 	default:
 		panic(fmt.Sprintf("wrong token %d in command.finalize()", c.token))
 	}
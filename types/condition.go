@@ -0,0 +1,135 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//Condition holds a where clause that limits a style command to cells with certain contents,
+//eg style col 2:max where text ~ "^0\.0" highlight or style row 2:max where col 4 < 0.05 significant
+type Condition struct {
+	//Subject is text to test the text of each cell, col to test the cell in col Index of the same row
+	//or row to test the cell in row Index of the same col
+	Subject string
+	Index   int    //row or col number; may be relative to the last row or col until normalized
+	Op      string //one of ~ (matches regexp), !~, =, !=, <, <=, >, >=
+	Value   string
+	re      *regexp.Regexp
+	number  float64
+	numeric bool //true if Value is a number without a bound
+}
+
+//NewCondition validates and returns a Condition
+func NewCondition(subject string, index int, op, value string) (*Condition, error) {
+	c := &Condition{Subject: subject, Index: index, Op: op, Value: value}
+	switch subject {
+	case "text":
+	case "row", "col":
+		if index == RwMissing {
+			return nil, fmt.Errorf("missing %s number in where clause", subject)
+		}
+	default:
+		return nil, fmt.Errorf("expected text, row or col after where, found %s", subject)
+	}
+	var bound string
+	c.number, bound, c.numeric = ParseNumber(value)
+	c.numeric = c.numeric && bound == ""
+	switch op {
+	case "~", "!~":
+		var err error
+		if c.re, err = regexp.Compile(value); err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q in where clause: %s", value, err)
+		}
+	case "=", "!=":
+	case "<", "<=", ">", ">=":
+		if !c.numeric {
+			return nil, fmt.Errorf("operator %s needs a number, found %q", op, value)
+		}
+	default:
+		return nil, fmt.Errorf("invalid operator %q in where clause", op)
+	}
+	return c, nil
+}
+
+func (c *Condition) String() string {
+	subject := c.Subject
+	if subject != "text" {
+		subject += " " + string(formattedCellCoord(c.Index))
+	}
+	value := c.Value
+	if !c.numeric { //quoted as in the source; other escapes, which are common in regexps, are kept as is
+		value = `"` + strings.Replace(value, `"`, `\"`, -1) + `"`
+	}
+	return fmt.Sprintf("where %s %s %s", subject, c.Op, value)
+}
+
+//Normalize resolves a row or col number that is relative to the last row or col and checks that it lies
+//within the table
+func (c *Condition) Normalize(rowCount, colCount int) error {
+	count := colCount
+	switch c.Subject {
+	case "text":
+		return nil
+	case "row":
+		count = rowCount
+	}
+	if IsFromEnd(c.Index) {
+		c.Index = count - (RwMax - c.Index)
+	}
+	if c.Index < 1 || c.Index > count {
+		return fmt.Errorf("%s %s in where clause is outside the table, which has %d %ss", c.Subject,
+			formattedCellCoord(c.Index), count, c.Subject)
+	}
+	return nil
+}
+
+//Match returns true if text satisfies the condition. Comparisons with a number need text that holds a number
+//(see ParseNumber); other text, eg NA or 1.2 (0.9-1.5), only satisfies != and = NA. A bound, eg <0.001,
+//satisfies < and <= only if all the values below it do and > and >= only if all the values above it do,
+//so <0.001 satisfies < 0.001 but not > 0.0005; otherwise it is compared as text
+func (c *Condition) Match(text string) bool {
+	text = strings.TrimSpace(text)
+	switch c.Op {
+	case "~":
+		return c.re.MatchString(text)
+	case "!~":
+		return !c.re.MatchString(text)
+	}
+	n, bound, ok := ParseNumber(text)
+	switch {
+	case !ok || !c.numeric:
+	case bound == "<" && (c.Op == "<" || c.Op == "<="):
+		return n <= c.number
+	case bound == ">" && (c.Op == ">" || c.Op == ">="):
+		return n >= c.number
+	case bound != "":
+		ok = false
+	}
+	if !c.numeric || !ok {
+		switch c.Op {
+		case "=":
+			return text == c.Value
+		case "!=":
+			return text != c.Value
+		}
+		return false
+	}
+	switch c.Op {
+	case "=":
+		return n == c.number
+	case "!=":
+		return n != c.number
+	case "<":
+		return n < c.number
+	case "<=":
+		return n <= c.number
+	case ">":
+		return n > c.number
+	case ">=":
+		return n >= c.number
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package types
+
+import "testing"
+
+func TestCondition_Match(t *testing.T) {
+	tests := []struct {
+		op, value string
+		text      string
+		want      bool
+	}{
+		{"<", "0.05", "0.049", true},
+		{"<", "0.05", "<0.001", true},
+		{"<", "0.001", "<0.001", true},
+		{"<=", "0.0005", "<0.001", false},
+		{">", "0.0005", "<0.001", false},
+		{">=", "0.001", "<0.001", false},
+		{"=", "0.001", "<0.001", false},
+		{"!=", "0.001", "<0.001", true},
+		{">", "0.99", ">0.99", true},
+		{">=", "0.5", ">0.99", true},
+		{">", "1", ">0.99", false},
+		{"<", "1", ">0.99", false},
+		{"<", "0.05", "0.05", false},
+		{"<=", "0.05", " 0.05 ", true},
+		{">", "1000", "1,234.5", true},
+		{">", "1000", "1,234.5 (1.2-3.4)", false},
+		{"=", "12", "12abc", false},
+		{"<", "1", "1.2e-05", true},
+		{">=", "-1", "-0.5", true},
+		{"<", "0.05", "NA", false},
+		{"<", "0.05", "", false},
+		{"=", "1", "1.0", true},
+		{"=", "NA", "NA", true},
+		{"!=", "1", "NA", true},
+		{"!=", "1", "2", true},
+		{"~", `^0\.0`, "0.03", true},
+		{"~", `^0\.0`, "0.3", false},
+		{"!~", `^0\.0`, "0.3", true},
+	}
+	for _, tt := range tests {
+		c, err := NewCondition("text", RwMissing, tt.op, tt.value)
+		if err != nil {
+			t.Fatalf("NewCondition(%s %s) error = %v", tt.op, tt.value, err)
+		}
+		if got := c.Match(tt.text); got != tt.want {
+			t.Errorf("%s.Match(%q) = %t, want %t", c, tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package types
+
+//Document holds page layout info used by renderers that produce paged output, eg docx.
+//All lengths are in twentieths of a point (twips); 1440 twips = 1 inch
+type Document struct {
+	ID               string
+	TemplateFileName string
+	InputDir         string
+	Sections         []*DocumentSection
+	HeadersFooters   []*HeaderFooter
+}
+
+//DocumentSection describes the page settings of a document section
+type DocumentSection struct {
+	ID                             string
+	InputDir                       string
+	AddPageBreakAfterEachInputFile bool
+	Contents                       string
+	Props                          SectionProps
+}
+
+//SectionProps holds page size, margins and references to the headers and footers used in a section
+type SectionProps struct {
+	Size           PageSize
+	Margins        PageMargins
+	HeadersFooters []*SectionHeaderFooter
+}
+
+//PageSize holds page dimensions and orientation ("portrait" or "landscape")
+type PageSize struct {
+	Width       int
+	Height      int
+	Orientation string
+}
+
+//PageMargins holds page margins
+type PageMargins struct {
+	Top    int
+	Right  int
+	Bottom int
+	Left   int
+	Header int
+	Footer int
+	Gutter int
+}
+
+//SectionHeaderFooter references a HeaderFooter by its ID. HFType is one of "default", "first" or "even"
+type SectionHeaderFooter struct {
+	ID     string
+	HFType string
+}
+
+//HeaderFooter holds the contents of a page header or footer. Footers are identified by an ID starting with "footer".
+//Contents may include ${word: FIELD} (eg ${word: PAGE}) and ${htmldocx: timestamp} placeholders
+type HeaderFooter struct {
+	ID                             string
+	InputDir                       string
+	AddPageBreakAfterEachInputFile bool
+	Contents                       string
+}
+
+//DefaultDocument returns a document with one US-letter portrait section with 1-inch margins
+func DefaultDocument() *Document {
+	return &Document{
+		Sections: []*DocumentSection{{
+			ID:                             "section1",
+			AddPageBreakAfterEachInputFile: true,
+			Props: SectionProps{
+				Size:    PageSize{Width: 12240, Height: 15840, Orientation: "portrait"},
+				Margins: PageMargins{Top: 1440, Right: 1440, Bottom: 1440, Left: 1440, Header: 360, Footer: 360},
+			},
+		}},
+	}
+}
+
+//Section returns the first document section or a default one if none was specified
+func (d *Document) Section() *DocumentSection {
+	if d == nil || len(d.Sections) == 0 || d.Sections[0] == nil {
+		return DefaultDocument().Sections[0]
+	}
+	return d.Sections[0]
+}
+
+//HeaderFooter returns the header or footer with the specified ID or nil if none found
+func (d *Document) HeaderFooter(id string) *HeaderFooter {
+	if d == nil {
+		return nil
+	}
+	for _, hf := range d.HeadersFooters {
+		if hf != nil && hf.ID == id {
+			return hf
+		}
+	}
+	return nil
+}
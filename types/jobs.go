@@ -23,7 +23,8 @@ type Job struct {
 	RunOptions *core.RunOptions
 	// SaveConvertedFile bool
 	RosewoodSettings *RosewoodSettings
-	UI               ui.UI `mdson:"-"` // provides access to the UI for lower-level routines
+	Document         *Document // page settings used by paged renderers eg docx
+	UI               ui.UI     `mdson:"-"` // provides access to the UI for lower-level routines
 }
 
 //DefaultJob returns default job
@@ -33,6 +34,7 @@ func DefaultJob(settings *RosewoodSettings) *Job {
 			Command: "run",
 		},
 		RosewoodSettings: settings,
+		Document:         DefaultDocument(),
 		UI:               ui.NewUI(0), //default ui
 	}
 }
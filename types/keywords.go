@@ -11,16 +11,22 @@ const (
 	catTableCmdBegin
 	KwMerge
 	KwStyle
+	KwHeader
+	KwFormat
+	KwAlign
 	catTableCmdEnd
 	KwSet
 	KwUse
 )
 
 var keywords = map[string]RwKeyWord{
-	"merge": KwMerge,
-	"style": KwStyle,
-	"set":   KwSet,
-	"use":   KwUse,
+	"merge":  KwMerge,
+	"style":  KwStyle,
+	"header": KwHeader,
+	"format": KwFormat,
+	"align":  KwAlign,
+	"set":    KwSet,
+	"use":    KwUse,
 }
 
 //LookupKeyword returns isKeyWord=true and corresponding keyword id if name is keyword;
@@ -0,0 +1,259 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//maxDecimals is the largest number of decimals accepted by format commands
+const maxDecimals = 15
+
+//NumberFormat holds the options of a format command, eg format col 2:3 decimals 2 thousands ","
+//or format col 4 pvalue "<0.001"
+type NumberFormat struct {
+	Decimals  int    //number of decimals; RwMissing to keep the decimals of each cell
+	Thousands string //separator inserted between groups of 3 digits of the integer part, if any
+	Percent   bool   //multiply by 100 and append %
+	PValue    string //label of values below the threshold it contains, eg <0.001
+	threshold decimal
+}
+
+//NewNumberFormat returns a NumberFormat from the arguments of a format command, which are option names
+//each followed by its value (except percent), eg decimals 2 thousands ","
+func NewNumberFormat(args []string) (*NumberFormat, error) {
+	f := &NumberFormat{Decimals: RwMissing}
+	value := func(i int) (string, error) {
+		if i+1 >= len(args) {
+			return "", fmt.Errorf("missing value for %s", args[i])
+		}
+		if s, err := strconv.Unquote(args[i+1]); err == nil {
+			return s, nil
+		}
+		return args[i+1], nil
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("expected decimals, thousands, percent or pvalue")
+	}
+	for i := 0; i < len(args); i++ {
+		var err error
+		switch args[i] {
+		case "percent":
+			f.Percent = true
+			continue
+		case "decimals":
+			var s string
+			if s, err = value(i); err != nil {
+				return nil, err
+			}
+			if f.Decimals, err = strconv.Atoi(s); err != nil || f.Decimals < 0 || f.Decimals > maxDecimals {
+				return nil, fmt.Errorf("decimals must be a number between 0 and %d, found %s", maxDecimals, args[i+1])
+			}
+		case "thousands":
+			if f.Thousands, err = value(i); err != nil {
+				return nil, err
+			}
+		case "pvalue":
+			if f.PValue, err = value(i); err != nil {
+				return nil, err
+			}
+			bound, d, ok := parseNumber(f.PValue)
+			if !ok || bound != "<" || d.neg || d.isZero() {
+				return nil, fmt.Errorf(`pvalue must be < followed by a positive number, eg "<0.001", found %s`, args[i+1])
+			}
+			f.threshold = d
+		default:
+			return nil, fmt.Errorf("expected decimals, thousands, percent or pvalue, found %s", args[i])
+		}
+		i++ //skip the value
+	}
+	return f, nil
+}
+
+//Apply returns text formatted according to f. Only text that holds a number (see ParseNumber) is formatted;
+//other text, eg NA or 1.2 (0.9-1.5), is returned as is. Surrounding spaces are kept. Numbers are rounded half
+//away from zero using their decimal digits, so 2.675 is rounded to 2.68
+func (f *NumberFormat) Apply(text string) string {
+	prefix, d, ok := parseNumber(text)
+	if !ok {
+		return text
+	}
+	decimals := f.Decimals
+	if f.PValue != "" {
+		if decimals == RwMissing { //use the precision of the threshold, eg 3 for <0.001
+			decimals = f.threshold.decimals()
+		}
+		if prefix != ">" && d.less(f.threshold) || prefix == "<" && !f.threshold.less(d) {
+			return strings.Replace(text, strings.TrimSpace(text), f.PValue, 1)
+		}
+	}
+	if f.Percent && !d.isZero() {
+		d.point += 2
+	}
+	s := prefix + d.format(decimals, f.Thousands)
+	if f.Percent {
+		s += "%"
+	}
+	return strings.Replace(text, strings.TrimSpace(text), s, 1)
+}
+
+//decimal holds a number as a string of decimal digits so that it can be rounded and formatted exactly as written,
+//avoiding the surprises of binary floating point (eg 2.675 being rounded to 2.67)
+type decimal struct {
+	neg    bool
+	digits string //without leading zeros
+	point  int    //position of the decimal point relative to the first digit; may be negative or > len(digits)
+}
+
+var decimalNumber = regexp.MustCompile(`^([-+]?)(\d{1,3}(?:,\d{3})+|\d*)(?:\.(\d*))?(?:[eE]([-+]?\d+))?$`)
+
+//ParseNumber returns the number held by s and the bound before it, if any: < or > as in p-values reported
+//as <0.001. Apart from surrounding spaces, s must hold a single number that may include thousands separators
+//(commas) and an exponent, eg 1,234.5, -1.2e-05 or <0.001; other text, eg NA, 12abc or 1.2 (0.9-1.5), is not
+//a number. Where clauses and format commands use the same rules
+func ParseNumber(s string) (n float64, bound string, ok bool) {
+	bound, d, ok := parseNumber(s)
+	if !ok {
+		return 0, "", false
+	}
+	n, err := strconv.ParseFloat(d.format(RwMissing, ""), 64)
+	return n, bound, err == nil
+}
+
+//parseNumber returns the bound and the decimal number held by s, see ParseNumber
+func parseNumber(s string) (bound string, d decimal, ok bool) {
+	s = strings.TrimSpace(s)
+	if s != "" && (s[0] == '<' || s[0] == '>') {
+		bound, s = s[:1], strings.TrimSpace(s[1:])
+	}
+	if d, ok = parseDecimal(s); !ok {
+		return "", decimal{}, false
+	}
+	return bound, d, true
+}
+
+//parseDecimal parses a number that may include thousands separators (commas) and an exponent, eg 1,234.5 or 1.2e-05
+func parseDecimal(s string) (decimal, bool) {
+	m := decimalNumber.FindStringSubmatch(s)
+	if m == nil || m[2] == "" && m[3] == "" {
+		return decimal{}, false
+	}
+	intPart := strings.Replace(m[2], ",", "", -1)
+	d := decimal{neg: m[1] == "-", digits: intPart + m[3], point: len(intPart)}
+	if m[4] != "" {
+		exp, err := strconv.Atoi(m[4])
+		if err != nil || exp > 1000 || exp < -1000 {
+			return decimal{}, false
+		}
+		d.point += exp
+	}
+	for len(d.digits) > 0 && d.digits[0] == '0' {
+		d.digits = d.digits[1:]
+		d.point--
+	}
+	if d.digits == "" { //keep the decimals of zero, eg 0.00
+		d.point = 0
+		d.digits = strings.Repeat("0", len(m[3]))
+	}
+	return d, true
+}
+
+func (d decimal) isZero() bool {
+	return strings.Trim(d.digits, "0") == ""
+}
+
+//decimals returns the number of digits after the decimal point
+func (d decimal) decimals() int {
+	if n := len(d.digits) - d.point; n > 0 {
+		return n
+	}
+	return 0
+}
+
+//less returns true if d < other; only used for non-negative numbers
+func (d decimal) less(other decimal) bool {
+	if d.neg != other.neg {
+		return d.neg
+	}
+	switch {
+	case d.isZero() || other.isZero():
+		return d.isZero() && !other.isZero()
+	case d.point != other.point:
+		return d.point < other.point
+	}
+	a, b := d.digits, other.digits
+	for len(a) < len(b) {
+		a += "0"
+	}
+	for len(b) < len(a) {
+		b += "0"
+	}
+	return a < b
+}
+
+//round returns d rounded half away from zero to n decimals
+func (d decimal) round(n int) decimal {
+	keep := d.point + n //number of digits kept
+	switch {
+	case keep < 0 || d.isZero():
+		return decimal{neg: d.neg}
+	case keep >= len(d.digits):
+		return d
+	}
+	rounded := []byte(d.digits[:keep])
+	if d.digits[keep] >= '5' {
+		i := len(rounded) - 1
+		for ; i >= 0 && rounded[i] == '9'; i-- {
+			rounded[i] = '0'
+		}
+		if i < 0 { //eg 9.96 rounded to 10.0
+			rounded = append([]byte{'1'}, rounded...)
+			d.point++
+		} else {
+			rounded[i]++
+		}
+	}
+	d.digits = string(rounded)
+	return d
+}
+
+//format returns d with n decimals (or its own decimals if n is RwMissing) and the integer part grouped by sep
+func (d decimal) format(n int, sep string) string {
+	if n != RwMissing {
+		d = d.round(n)
+	} else {
+		n = d.decimals()
+	}
+	intPart, fracPart := "0", ""
+	switch {
+	case d.point >= len(d.digits):
+		intPart = d.digits + strings.Repeat("0", d.point-len(d.digits))
+	case d.point > 0:
+		intPart, fracPart = d.digits[:d.point], d.digits[d.point:]
+	default:
+		fracPart = strings.Repeat("0", -d.point) + d.digits
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	if len(fracPart) > n {
+		fracPart = fracPart[:n]
+	}
+	fracPart += strings.Repeat("0", n-len(fracPart))
+	if sep != "" {
+		for i := len(intPart) - 3; i > 0; i -= 3 {
+			intPart = intPart[:i] + sep + intPart[i:]
+		}
+	}
+	s := intPart
+	if fracPart != "" {
+		s += "." + fracPart
+	}
+	if d.neg && strings.Trim(s, "0.,"+sep) != "" { //no negative zero, eg -0.001 rounded to 0.00
+		s = "-" + s
+	}
+	return s
+}
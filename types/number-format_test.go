@@ -0,0 +1,99 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package types
+
+import "testing"
+
+func TestNumberFormat_Apply(t *testing.T) {
+	tests := []struct {
+		args []string
+		text string
+		want string
+	}{
+		{[]string{"decimals", "2"}, "2.675", "2.68"},
+		{[]string{"decimals", "2"}, "-2.675", "-2.68"},
+		{[]string{"decimals", "2"}, "1.5", "1.50"},
+		{[]string{"decimals", "1"}, "9.96", "10.0"},
+		{[]string{"decimals", "0"}, "0.5", "1"},
+		{[]string{"decimals", "2"}, "-0.001", "0.00"},
+		{[]string{"decimals", "3"}, "1.2e-05", "0.000"},
+		{[]string{"decimals", "2"}, " 0.123 ", " 0.12 "},
+		{[]string{"decimals", "2"}, "NA", "NA"},
+		{[]string{"decimals", "2"}, "1.2 (0.9-1.5)", "1.2 (0.9-1.5)"},
+		{[]string{"decimals", "2"}, "12abc", "12abc"},
+		{[]string{"decimals", "2"}, "", ""},
+		{[]string{"thousands", `","`}, "1234567.891", "1,234,567.891"},
+		{[]string{"thousands", `" "`, "decimals", "1"}, "-1234.56", "-1 234.6"},
+		{[]string{"thousands", `","`}, "1,234", "1,234"},
+		{[]string{"decimals", "1"}, "1,234.56", "1234.6"},
+		{[]string{"percent"}, "0.1234", "12.34%"},
+		{[]string{"percent", "decimals", "1"}, "0.07", "7.0%"},
+		{[]string{"percent"}, "1.5", "150%"},
+		{[]string{"percent"}, "0", "0%"},
+		{[]string{"pvalue", `"<0.001"`}, "0.0004", "<0.001"},
+		{[]string{"pvalue", `"<0.001"`}, "0.00096", "<0.001"},
+		{[]string{"pvalue", `"<0.001"`}, "0.001", "0.001"},
+		{[]string{"pvalue", `"<0.001"`}, "0.04567", "0.046"},
+		{[]string{"pvalue", `"<0.001"`}, "<0.0001", "<0.001"},
+		{[]string{"pvalue", `"<0.001"`}, ">0.99", ">0.990"},
+		{[]string{"pvalue", `"<0.01"`, "decimals", "3"}, "0.0123", "0.012"},
+	}
+	for _, tt := range tests {
+		f, err := NewNumberFormat(tt.args)
+		if err != nil {
+			t.Fatalf("NewNumberFormat(%v) error = %v", tt.args, err)
+		}
+		if got := f.Apply(tt.text); got != tt.want {
+			t.Errorf("NumberFormat(%v).Apply(%q) = %q, want %q", tt.args, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestNewNumberFormat(t *testing.T) {
+	tests := [][]string{
+		nil,
+		{"decimals"},
+		{"decimals", "x"},
+		{"decimals", "16"},
+		{"thousands"},
+		{"pvalue", `"0.001"`},
+		{"pvalue", `"<0"`},
+		{"bold"},
+	}
+	for _, args := range tests {
+		if _, err := NewNumberFormat(args); err == nil {
+			t.Errorf("NewNumberFormat(%v): expected an error", args)
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	f, _ := NewNumberFormat([]string{"decimals", "1"})
+	tests := []struct {
+		text  string
+		n     float64
+		bound string
+		ok    bool
+	}{
+		{" 1,234.56 ", 1234.56, "", true},
+		{"-1.2e-05", -1.2e-05, "", true},
+		{"<0.001", 0.001, "<", true},
+		{"> 0.99", 0.99, ">", true},
+		{".5", 0.5, "", true},
+		{"NA", 0, "", false},
+		{"12abc", 0, "", false},
+		{"1.2 (0.9-1.5)", 0, "", false},
+		{"<", 0, "", false},
+		{"", 0, "", false},
+	}
+	for _, tt := range tests {
+		n, bound, ok := ParseNumber(tt.text)
+		if n != tt.n || bound != tt.bound || ok != tt.ok {
+			t.Errorf("ParseNumber(%q) = %v, %q, %t, want %v, %q, %t", tt.text, n, bound, ok, tt.n, tt.bound, tt.ok)
+		}
+		//format commands change exactly the cells that where clauses compare as numbers
+		if changed := f.Apply(tt.text) != tt.text; changed != tt.ok {
+			t.Errorf("Apply(%q) changed the text: %t, want %t", tt.text, changed, tt.ok)
+		}
+	}
+}
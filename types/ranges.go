@@ -13,8 +13,21 @@ const (
 	RwMissing = 1<<63 - 1     //get the max int64 for use as a sentinel for missing values
 	RwMax     = RwMissing - 1 //use this as the Maxint
 	RwMin     = 1
+	//coordinates in (RwMax-rwMaxOffset, RwMax] are relative to the last row or col
+	rwMaxOffset = 1 << 30
 )
 
+//FromEnd returns a coordinate that refers to the row or col that lies offset rows or cols before
+//the last one; FromEnd(0) is the last row or col (max). It is resolved by Span.Normalize
+func FromEnd(offset int) int {
+	return RwMax - offset
+}
+
+//IsFromEnd returns true if coordinate is relative to the last row or col, eg max or max-2
+func IsFromEnd(coordinate int) bool {
+	return coordinate <= RwMax && coordinate > RwMax-rwMaxOffset
+}
+
 //Coordinates holds the row, col of a table cell
 type Coordinates struct {
 	Row, Col int
@@ -28,6 +41,11 @@ func formattedCellCoord(value int) []byte { //return byte array for ease of conc
 	case RwMax:
 		buf = append(buf, 'm', 'a', 'x') //use max for missing
 	default:
+		if IsFromEnd(value) { //eg max-2
+			buf = append(buf, 'm', 'a', 'x', '-')
+			buf = strconv.AppendInt(buf, int64(RwMax-value), 10)
+			break
+		}
 		buf = strconv.AppendInt(buf, int64(value), 10)
 	}
 	return buf
@@ -45,15 +63,16 @@ type Range struct {
 	TopLeft     Coordinates
 	BottomRight Coordinates
 	styleList   []string
+	condition   *Condition //if not nil, styles apply only to cells that satisfy it
 }
 
 //newRange return an empty a range
 func newRange() Range {
-	return Range{Coordinates{RwMin, RwMin}, Coordinates{RwMissing, RwMissing}, nil} //assume topleft =(1,1)
+	return Range{TopLeft: Coordinates{RwMin, RwMin}, BottomRight: Coordinates{RwMissing, RwMissing}} //assume topleft =(1,1)
 }
 
 func makeRange(tlr, tlc, brr, brc int) Range {
-	return Range{Coordinates{tlr, tlc}, Coordinates{brr, brc}, nil}
+	return Range{TopLeft: Coordinates{tlr, tlc}, BottomRight: Coordinates{brr, brc}}
 }
 
 // func (r Range) String() string {
@@ -73,6 +92,11 @@ func (r *Range) Styles() []string {
 	return r.styleList
 }
 
+//Condition returns the where clause that selects the cells of the range that get its styles, or nil if all do
+func (r *Range) Condition() *Condition {
+	return r.condition
+}
+
 //AddStyle adds one or more style names if they do not already exist in the list
 //sufficiently efficient for short lists and avoids allocating a map
 func (r *Range) addStyle(styles ...string) error {
@@ -115,6 +139,7 @@ func GetAllRanges(cmdList []*Command, cmdType RwKeyWord) (allRangesList []Range,
 		for i := range rList {
 			if cmdType == KwStyle {
 				rList[i].addStyle(cmd.Args()...)
+				rList[i].condition = cmd.condition
 			}
 		}
 		allRangesList = append(allRangesList, rList...)
@@ -115,21 +115,31 @@ func Test_normalizeSpan(t *testing.T) {
 		colCount int
 	}
 	tests := []struct {
-		name string
-		args args
-		want *Span
+		name    string
+		args    args
+		want    *Span
+		wantErr bool
 	}{
-		{"no-missing", args{MakeSpan(1, 1, 4, 4), 6, 4}, MakeSpan(1, 1, 4, 4)},
-		{"r1-r2-missing", args{MakeSpan(RwMissing, RwMissing, 2, 3), 6, 4}, MakeSpan(1, 6, 2, 3)},
-		{"c1-c2-missing", args{MakeSpan(1, 1, RwMissing, RwMissing), 6, 4}, MakeSpan(1, 1, 1, 4)},
-		{"r2missing", args{MakeSpan(1, RwMissing, 2, 2), 6, 4}, MakeSpan(1, 1, 2, 2)},
-		{"c2missing", args{MakeSpan(1, 1, 2, RwMissing), 6, 4}, MakeSpan(1, 1, 2, 2)},
-		{"r1-r2-c2-missing", args{MakeSpan(RwMissing, RwMissing, RwMissing, 3), 6, 4}, MakeSpan(1, 6, 3, 3)},
+		{"no-missing", args{MakeSpan(1, 1, 4, 4), 6, 4}, MakeSpan(1, 1, 4, 4), false},
+		{"r1-r2-missing", args{MakeSpan(RwMissing, RwMissing, 2, 3), 6, 4}, MakeSpan(1, 6, 2, 3), false},
+		{"c1-c2-missing", args{MakeSpan(1, 1, RwMissing, RwMissing), 6, 4}, MakeSpan(1, 1, 1, 4), false},
+		{"r2missing", args{MakeSpan(1, RwMissing, 2, 2), 6, 4}, MakeSpan(1, 1, 2, 2), false},
+		{"c2missing", args{MakeSpan(1, 1, 2, RwMissing), 6, 4}, MakeSpan(1, 1, 2, 2), false},
+		{"r1-r2-c2-missing", args{MakeSpan(RwMissing, RwMissing, RwMissing, 3), 6, 4}, MakeSpan(1, 6, 3, 3), false},
+		{"r2-max", args{MakeSpan(2, RwMax, 1, 1), 6, 4}, MakeSpan(2, 6, 1, 1), false},
+		{"r1-max-r2-missing", args{MakeSpan(RwMax, RwMissing, 1, 1), 6, 4}, MakeSpan(6, 6, 1, 1), false},
+		{"from-end", args{MakeSpan(FromEnd(2), RwMax, FromEnd(1), FromEnd(1)), 6, 4}, MakeSpan(4, 6, 3, 3), false},
+		{"first-row-from-end", args{MakeSpan(FromEnd(5), RwMissing, 1, 1), 6, 4}, MakeSpan(1, 1, 1, 1), false},
+		{"before-first-row", args{MakeSpan(FromEnd(6), RwMax, 1, 1), 6, 4}, nil, true},
+		{"before-first-col", args{MakeSpan(1, 1, FromEnd(4), RwMissing), 6, 4}, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tt.args.cs.Normalize(tt.args.rowCount, tt.args.colCount)
-			if !reflect.DeepEqual(tt.args.cs, tt.want) {
+			err := tt.args.cs.Normalize(tt.args.rowCount, tt.args.colCount)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeSpan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(tt.args.cs, tt.want) {
 				t.Errorf("normalizeSpan() = %v, want %v", tt.args.cs, tt.want)
 			}
 		})
@@ -2,13 +2,17 @@ package types
 
 //RosewoodSettings for controlling Rosewood lib
 type RosewoodSettings struct {
-	CheckSyntaxOnly    bool   `mdson:"-"`
-	ColumnSeparator    string `mdson:"-"`
+	CheckSyntaxOnly    bool `mdson:"-"`
+	ColumnSeparator    string
 	ConvertOldVersions bool
 	ConvertFromVersion string
 	//controls printing debug info by internal lib routines
 	Debug                int
 	DoNotInlineCSS       bool
+	FragmentID           string //names the class and ids of an html fragment so that several can share a page; the input file name if empty
+	HTMLFragment         bool   //html output holds only the tables, without the document head and body
+	HTMLTemplateName     string //html/template file that replaces the page, table, row and cell layouts of html output
+	IndentLevels         bool   //leading spaces of first-column cells set their indent level, see table.Cell.Indent
 	MandatoryCol         bool   `mdson:"-"`
 	MarkdownRender       string //"disabled", "strict", "standard"
 	MaxConcurrentWorkers int
@@ -16,10 +20,14 @@ type RosewoodSettings struct {
 	RangeOperator     int32 `mdson:"-"`
 	ReportAllError    bool
 	SaveConvertedFile bool
+	ScopedCSS         bool   //html fragments include the stylesheet limited to a wrapper div
 	SectionCapacity   int    `mdson:"-"`
 	SectionSeparator  string `mdson:"-"`
 	SectionsPerTable  int    `mdson:"-"`
 	StyleSheetName    string
+	StylesFileName    string //style definitions used by renderers other than html, see StyleRegistry
+	Theme             string //built-in or user html theme used if StyleSheetName is empty; default if empty
+	ThemePath         string //directories searched for user themes (name.css), separated by os.PathListSeparator
 	TrimCellContents  bool
 }
 
@@ -9,10 +9,12 @@ import (
 //as specified in a Rosewood command. Should be translated to one or more spans
 //in the physical table
 type SpanSegment struct {
-	kind        string // row or col
-	Left, Right int    // e.g., row 1:2
-	By          int    // holds the step in eg row 1:2:6
-	List        []int  // holds list of row/col numbers eg row 1,2,3,4
+	kind        string       // row or col
+	Left, Right int          // e.g., row 1:2
+	By          int          // holds the step in eg row 1:2:6
+	List        []int        // holds list of row/col numbers eg row 1,2,3,4
+	Label       string       // text of the header or first column cell that identifies the row/col eg col "Total"
+	Except      *SpanSegment // rows/cols excluded from this segment eg row 2:max except row 5,9
 }
 
 //NewSpanSegment returns a segment of certain kind
@@ -20,9 +22,17 @@ func NewSpanSegment(kind string) SpanSegment {
 	return SpanSegment{kind: kind, Left: RwMissing, Right: RwMissing, By: RwMissing}
 }
 
+//Kind returns row or col
+func (ss *SpanSegment) Kind() string {
+	return ss.kind
+}
+
 func (ss *SpanSegment) String() string {
 	buf := &bytes.Buffer{}
 	fmt.Fprintf(buf, "%s ", ss.kind)
+	if ss.Label != "" {
+		fmt.Fprintf(buf, "%q", ss.Label)
+	}
 	if ss.Left != RwMissing {
 		fmt.Fprintf(buf, "%s", formattedCellCoord(ss.Left))
 		if ss.By != RwMissing {
@@ -40,5 +50,36 @@ func (ss *SpanSegment) String() string {
 	if bytes.HasSuffix(buf.Bytes(), []byte{','}) {
 		buf.Truncate(buf.Len() - 1)
 	}
+	if ss.Except != nil {
+		fmt.Fprintf(buf, " except %s", ss.Except)
+	}
 	return buf.String()
 }
+
+//except returns a copy of the Except segment (if any) that can be resolved against a table without
+//changing the segment
+func (ss *SpanSegment) except() *SpanSegment {
+	if ss.Except == nil {
+		return nil
+	}
+	ex := *ss.Except
+	return &ex
+}
+
+//points returns the row/col numbers included in the segment. It is used for except segments, which are
+//neither normalized nor expanded into ranges
+func (ss *SpanSegment) points() ([]int, error) {
+	var points []int
+	switch {
+	case ss.Left == RwMissing: //list only
+	case ss.By != RwMissing:
+		if points = genAllPossibleRangePoints(ss.Left, ss.Right, ss.By); points == nil {
+			return nil, fmt.Errorf("invalid span %s", ss)
+		}
+	case ss.Right == RwMissing:
+		points = []int{ss.Left}
+	default:
+		points = genAllPossibleRangePoints(ss.Left, ss.Right, 1)
+	}
+	return append(points, ss.List...), nil
+}
@@ -8,9 +8,12 @@ import (
 
 //Span holds info on a physical range of a Rosewood table
 type Span struct {
-	r1, r2, c1, c2 int   //coordinates of the topleft and bottomrow cells in the span, maybe missing
-	rby, cby       int   //step increases in row and col
-	rcl, ccl       []int //list of row and cols when comma-separated list was specified
+	r1, r2, c1, c2 int          //coordinates of the topleft and bottomrow cells in the span, maybe missing
+	rby, cby       int          //step increases in row and col
+	rcl, ccl       []int        //list of row and cols when comma-separated list was specified
+	rLabel, cLabel string       //text identifying the row and col eg style col "Total"; resolved by ResolveLabels
+	rex, cex       *SpanSegment //rows and cols excluded from the span eg row 2:max except row 5,9
+	union          []*Span      //other blocks of cells added to the span eg row 1 col 2 union row 3 col 4
 }
 
 //NewSpan return new empty Span
@@ -33,11 +36,15 @@ func NewSpanFromSpanSegments(spanSegments []*SpanSegment) *Span {
 			s.r2 = segment.Right
 			s.rby = segment.By
 			s.rcl = segment.List
+			s.rLabel = segment.Label
+			s.rex = segment.except()
 		case "col":
 			s.c1 = segment.Left
 			s.c2 = segment.Right
 			s.cby = segment.By
 			s.ccl = segment.List
+			s.cLabel = segment.Label
+			s.cex = segment.except()
 		default:
 			panic("invalid SpanSegment in NewSpanFromSpanSegments()") //should never happen
 		}
@@ -62,29 +69,103 @@ func (s Span) testString() string {
 //Validate performs simple validation of the range coordinates
 func (s *Span) Validate() error {
 	//TODO: add by and rcl validation
-	//NOTE: row/col < 1 is prevented by the parser
+	//NOTE: row/col < 1 is prevented by the parser; end-relative coordinates (eg max-2) sort after absolute ones
 	if s.r1 > s.r2 {
-		return fmt.Errorf("top row number (%d) must be smaller than bottom row number (%d)", s.r1, s.r2)
+		return fmt.Errorf("top row number (%s) must be smaller than bottom row number (%s)", formattedCellCoord(s.r1), formattedCellCoord(s.r2))
 	}
 	if s.c1 > s.c2 {
-		return fmt.Errorf("Left column number (%d) must be smaller than Right column number (%d)", s.c1, s.c2)
+		return fmt.Errorf("Left column number (%s) must be smaller than Right column number (%s)", formattedCellCoord(s.c1), formattedCellCoord(s.c2))
+	}
+	for _, ex := range []*SpanSegment{s.rex, s.cex} {
+		if ex != nil && ex.Left > ex.Right {
+			return fmt.Errorf("invalid except %s: %s must be smaller than %s", ex, formattedCellCoord(ex.Left), formattedCellCoord(ex.Right))
+		}
+	}
+	for _, u := range s.union {
+		if err := u.Validate(); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-//Normalize replace missing values with values defined by rowCount and colCount
-func (s *Span) Normalize(rowCount, colCount int) {
-	if s.r2 == RwMax { //eg style 1:2:max; max is converted to RwMissing
-		s.r2 = RwMissing
+//HasLabels returns true if the row or col of the span is identified by text, eg style row "Total" bold
+func (s *Span) HasLabels() bool {
+	if s.rLabel != "" || s.cLabel != "" || s.rex != nil && s.rex.Label != "" || s.cex != nil && s.cex.Label != "" {
+		return true
+	}
+	for _, u := range s.union {
+		if u.HasLabels() {
+			return true
+		}
+	}
+	return false
+}
+
+//ResolveLabels replaces row and col labels with the coordinates returned by find, which is passed the kind
+//(row or col) and the text of the label and returns the first and last row or col that it identifies, eg
+//the cols spanned by a merged header cell
+func (s *Span) ResolveLabels(find func(kind, label string) (first, last int, err error)) error {
+	var err error
+	if s.rLabel != "" && s.r1 == RwMissing {
+		if s.r1, s.r2, err = find("row", s.rLabel); err != nil {
+			return err
+		}
 	}
-	if s.c2 == RwMax {
-		s.c2 = RwMissing
+	if s.cLabel != "" && s.c1 == RwMissing {
+		if s.c1, s.c2, err = find("col", s.cLabel); err != nil {
+			return err
+		}
+	}
+	for _, ex := range []*SpanSegment{s.rex, s.cex} {
+		if ex != nil && ex.Label != "" && ex.Left == RwMissing {
+			if ex.Left, ex.Right, err = find(ex.kind, ex.Label); err != nil {
+				return err
+			}
+		}
+	}
+	for _, u := range s.union {
+		if err = u.ResolveLabels(find); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Normalize resolves coordinates relative to the last row or col (eg max, max-2 or -1) and replaces missing values
+//with values defined by rowCount and colCount. It returns an error if a relative coordinate lies before the
+//first row or col
+func (s *Span) Normalize(rowCount, colCount int) error {
+	type coordinate struct {
+		coord *int
+		count int
+		kind  string
+	}
+	coords := []coordinate{{&s.r1, rowCount, "row"}, {&s.r2, rowCount, "row"}, {&s.c1, colCount, "col"}, {&s.c2, colCount, "col"}}
+	for _, ex := range []*SpanSegment{s.rex, s.cex} {
+		if ex != nil {
+			count := rowCount
+			if ex.kind == "col" {
+				count = colCount
+			}
+			coords = append(coords, coordinate{&ex.Left, count, ex.kind}, coordinate{&ex.Right, count, ex.kind})
+		}
+	}
+	for _, p := range coords {
+		if !IsFromEnd(*p.coord) {
+			continue
+		}
+		offset := RwMax - *p.coord
+		if offset >= p.count {
+			return fmt.Errorf("%s %s is outside the table, which has %d %ss", p.kind, formattedCellCoord(*p.coord), p.count, p.kind)
+		}
+		*p.coord = p.count - offset
 	}
-	if s.r1 == RwMissing && s.r2 == RwMissing { //span includes all rows eg style col 1
+	if s.r1 == RwMissing && s.r2 == RwMissing && len(s.rcl) == 0 { //span includes all rows eg style col 1
 		s.r1 = 1
 		s.r2 = rowCount
 	}
-	if s.c1 == RwMissing && s.c2 == RwMissing { //span includes all cols eg style row 1
+	if s.c1 == RwMissing && s.c2 == RwMissing && len(s.ccl) == 0 { //span includes all cols eg style row 1
 		s.c1 = 1
 		s.c2 = colCount
 	}
@@ -102,26 +183,24 @@ func (s *Span) Normalize(rowCount, colCount int) {
 	if s.c2 == RwMissing { // col x is equivalent to col x:x, eg style row 1,3 col 1
 		s.c2 = s.c1
 	}
+	for _, u := range s.union {
+		if err := u.Normalize(rowCount, colCount); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-//ExpandSpanToRanges convert by and comma list spans into one or more simple (topleft, bottomright) ranges
+//ExpandSpanToRanges convert by, comma list, except and union spans into one or more simple (topleft, bottomright) ranges
 func (s *Span) ExpandSpanToRanges() (rList []Range, err error) {
-	var rPoints, cPoints []int
-	//if skipped span (eg 1:2:10), generate Lists of all row and col points included
-	if s.rby != RwMissing {
-		if rPoints = genAllPossibleRangePoints(s.r1, s.r2, s.rby); rPoints == nil {
-			return nil, fmt.Errorf("invalid span %s", s)
-		}
+	rPoints, err := dimensionPoints(s.r1, s.r2, s.rby, s.rcl, s.rex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid span %s: %s", s, err)
 	}
-	if s.cby != RwMissing {
-		if cPoints = genAllPossibleRangePoints(s.c1, s.c2, s.cby); cPoints == nil {
-			return nil, fmt.Errorf("invalid span %s", s)
-		}
+	cPoints, err := dimensionPoints(s.c1, s.c2, s.cby, s.ccl, s.cex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid span %s: %s", s, err)
 	}
-	//if comma-separated, add to above Lists (which could be empty)
-	rPoints = append(rPoints, s.rcl...)
-	cPoints = append(cPoints, s.ccl...)
-
 	switch {
 	//scenario 1: simple (no steps or comma list) span, eg style row 1:3 col 1:2, return it
 	case len(rPoints) == 0 && len(cPoints) == 0:
@@ -148,9 +227,60 @@ func (s *Span) ExpandSpanToRanges() (rList []Range, err error) {
 			rList = append(rList, SpanToRange(MakeSpan(s.r1, s.r2, c, c)))
 		}
 	}
+	//scenario 5: union of several blocks eg row 1 col 2 union row 3 col 4
+	for _, u := range s.union {
+		uList, err := u.ExpandSpanToRanges()
+		if err != nil {
+			return nil, err
+		}
+		rList = append(rList, uList...)
+	}
 	return deduplicateRangeList(rList), nil
 }
 
+//dimensionPoints returns the rows (or cols) included in a span that uses a step, a comma list or except;
+//it returns nil if the span is a simple p1:p2 range
+func dimensionPoints(p1, p2, by int, list []int, except *SpanSegment) ([]int, error) {
+	var points []int
+	//if skipped span (eg 1:2:10), generate Lists of all row and col points included
+	switch {
+	case by != RwMissing:
+		if points = genAllPossibleRangePoints(p1, p2, by); points == nil {
+			return nil, fmt.Errorf("invalid step %d", by)
+		}
+	case p1 != RwMissing && (len(list) > 0 || except != nil): //eg row 1:3, 7 or row 1:3 except row 2
+		points = genAllPossibleRangePoints(p1, p2, 1)
+	}
+	//if comma-separated, add to above Lists (which could be empty)
+	points = append(points, list...)
+	if except == nil {
+		return points, nil
+	}
+	excluded, err := except.points()
+	if err != nil {
+		return nil, err
+	}
+	included := points[:0]
+	for _, p := range points {
+		if !containsInt(excluded, p) {
+			included = append(included, p)
+		}
+	}
+	if len(included) == 0 {
+		return nil, fmt.Errorf("except %s excludes all %ss", except, except.kind)
+	}
+	return included, nil
+}
+
+func containsInt(list []int, n int) bool {
+	for _, v := range list {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
+
 //deduplicateRangeList returns deduplicated (unique topleft, bottomright combo) range List
 func deduplicateRangeList(rList []Range) []Range {
 	if len(rList) < 2 { //nothing to deduplicate
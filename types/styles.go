@@ -0,0 +1,224 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package types
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//Style holds the formatting properties of a style name used in style commands. Renderers that cannot use css
+//classes query a StyleRegistry for these properties. Zero values mean that the style does not set the property,
+//so that the properties of several styles applied to the same cell can be combined (see StyleRegistry.Resolve)
+type Style struct {
+	Weight       string //bold or normal
+	Italic       bool
+	Align        string //left, center or right
+	Indent       int    //number of indent levels added to the cell
+	BorderTop    string //none, single, double or thick
+	BorderBottom string
+	BorderLeft   string
+	BorderRight  string
+	Shading      string //background colour as a hex RGB value, eg D9D9D9
+}
+
+//Bold returns true if the style sets a bold weight
+func (s Style) Bold() bool {
+	return s.Weight == "bold"
+}
+
+//merge sets the properties of s that are set in other
+func (s *Style) merge(other *Style) {
+	if other.Weight != "" {
+		s.Weight = other.Weight
+	}
+	s.Italic = s.Italic || other.Italic
+	if other.Align != "" {
+		s.Align = other.Align
+	}
+	s.Indent += other.Indent
+	for _, b := range []struct{ dst, src *string }{{&s.BorderTop, &other.BorderTop},
+		{&s.BorderBottom, &other.BorderBottom}, {&s.BorderLeft, &other.BorderLeft}, {&s.BorderRight, &other.BorderRight}} {
+		if *b.src != "" {
+			*b.dst = *b.src
+		}
+	}
+	if other.Shading != "" {
+		s.Shading = other.Shading
+	}
+}
+
+var hexColor = regexp.MustCompile(`^#?([0-9a-fA-F]{6})$`)
+
+//set sets the named property; value is ignored for italic
+func (s *Style) set(property, value string) error {
+	oneOf := func(dst *string, values ...string) error {
+		for _, v := range values {
+			if value == v {
+				*dst = value
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid %s %q, expected one of %s", property, value, strings.Join(values, ", "))
+	}
+	borders := []string{"none", "single", "double", "thick"}
+	switch property {
+	case "weight":
+		return oneOf(&s.Weight, "bold", "normal")
+	case "italic":
+		s.Italic = true
+	case "align":
+		return oneOf(&s.Align, "left", "center", "right")
+	case "indent":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid indent %q, expected a number of levels", value)
+		}
+		s.Indent = n
+	case "border":
+		if err := oneOf(&s.BorderTop, borders...); err != nil {
+			return err
+		}
+		s.BorderBottom, s.BorderLeft, s.BorderRight = value, value, value
+	case "border-top":
+		return oneOf(&s.BorderTop, borders...)
+	case "border-bottom":
+		return oneOf(&s.BorderBottom, borders...)
+	case "border-left":
+		return oneOf(&s.BorderLeft, borders...)
+	case "border-right":
+		return oneOf(&s.BorderRight, borders...)
+	case "shading":
+		m := hexColor.FindStringSubmatch(value)
+		if m == nil {
+			return fmt.Errorf("invalid shading %q, expected a hex RGB colour, eg #D9D9D9", value)
+		}
+		s.Shading = strings.ToUpper(m[1])
+	default:
+		return fmt.Errorf("unknown style property %s", property)
+	}
+	return nil
+}
+
+//StyleRegistry maps style names to their formatting properties
+type StyleRegistry struct {
+	styles map[string]*Style
+}
+
+//NewStyleRegistry returns an empty StyleRegistry
+func NewStyleRegistry() *StyleRegistry {
+	return &StyleRegistry{styles: make(map[string]*Style)}
+}
+
+//defaultStyles holds the definitions of the styles known to all renderers
+const defaultStyles = `
+bold          weight=bold
+italic        italic
+left          align=left
+center        align=center
+right         align=right
+indent        indent=1
+border-top    border-top=single
+border-bottom border-bottom=single
+border-left   border-left=single
+border-right  border-right=single
+shaded        shading=#D9D9D9
+`
+
+//DefaultStyleRegistry returns a StyleRegistry holding the commonly used styles: bold, italic, left, center,
+//right, indent, border-top, border-bottom, border-left, border-right and shaded
+func DefaultStyleRegistry() *StyleRegistry {
+	r := NewStyleRegistry()
+	if err := r.Load(strings.NewReader(defaultStyles)); err != nil {
+		panic(err) //should never happen
+	}
+	return r
+}
+
+//LoadStyleRegistry returns the default styles updated from the definitions in fileName, if not empty
+func LoadStyleRegistry(fileName string) (*StyleRegistry, error) {
+	r := DefaultStyleRegistry()
+	if fileName == "" {
+		return r, nil
+	}
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load style definitions: %s", err)
+	}
+	defer f.Close()
+	if err = r.Load(f); err != nil {
+		return nil, fmt.Errorf("invalid style definitions in %s: %s", fileName, err)
+	}
+	return r, nil
+}
+
+//Load reads style definitions, one per line, in the form name property=value ..., eg
+//
+//	total weight=bold border-top=single shading=#F2F2F2
+//
+//Properties are weight (bold or normal), italic, align (left, center or right), indent (number of levels),
+//border, border-top, border-bottom, border-left and border-right (none, single, double or thick) and shading
+//(hex RGB colour). Empty lines and lines starting with // are ignored. A definition replaces an existing
+//style with the same name
+func (r *StyleRegistry) Load(rd io.Reader) error {
+	scanner := bufio.NewScanner(rd)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "//") {
+			continue
+		}
+		s := &Style{}
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) == 1 && kv[0] != "italic" {
+				return fmt.Errorf("line %d: expected property=value, found %s", lineNum, field)
+			}
+			kv = append(kv, "")
+			if err := s.set(strings.ToLower(kv[0]), strings.ToLower(kv[1])); err != nil {
+				return fmt.Errorf("line %d: %s", lineNum, err)
+			}
+		}
+		r.Add(strings.ToLower(fields[0]), s)
+	}
+	return scanner.Err()
+}
+
+//Add adds or replaces the style called name
+func (r *StyleRegistry) Add(name string, s *Style) {
+	r.styles[name] = s
+}
+
+//Lookup returns the style called name or false if not found
+func (r *StyleRegistry) Lookup(name string) (*Style, bool) {
+	s, ok := r.styles[name]
+	return s, ok
+}
+
+//Names returns the sorted names of all styles
+func (r *StyleRegistry) Names() []string {
+	names := make([]string, 0, len(r.styles))
+	for name := range r.styles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//Resolve returns the combined properties of the named styles; properties set by later styles replace those set
+//by earlier ones, except indent levels, which add up. Unknown names (eg classes only defined in a css file)
+//are ignored
+func (r *StyleRegistry) Resolve(names []string) Style {
+	var s Style
+	for _, name := range names {
+		if style, ok := r.styles[name]; ok {
+			s.merge(style)
+		}
+	}
+	return s
+}
@@ -0,0 +1,68 @@
+// Copyright 2017 Salah Mahmud and Colleagues. All rights reserved.
+
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStyleRegistry_Load(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		style   string
+		want    Style
+		wantErr string
+	}{
+		{"all properties", "total weight=bold italic align=Right indent=2 border-top=double shading=#f2f2f2", "total",
+			Style{Weight: "bold", Italic: true, Align: "right", Indent: 2, BorderTop: "double", Shading: "F2F2F2"}, ""},
+		{"all borders", "// comment\n\nboxed border=thick", "boxed",
+			Style{BorderTop: "thick", BorderBottom: "thick", BorderLeft: "thick", BorderRight: "thick"}, ""},
+		{"replaces default", "bold weight=normal", "bold", Style{Weight: "normal"}, ""},
+		{"missing value", "total bold", "", Style{}, "line 1: expected property=value, found bold"},
+		{"unknown property", "total colour=red", "", Style{}, "line 1: unknown style property colour"},
+		{"invalid align", "total align=justify", "", Style{}, `invalid align "justify"`},
+		{"invalid indent", "total\nsub indent=-1", "", Style{}, `line 2: invalid indent "-1"`},
+		{"invalid shading", "total shading=grey", "", Style{}, `invalid shading "grey"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := DefaultStyleRegistry()
+			err := r.Load(strings.NewReader(tt.src))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			s, ok := r.Lookup(tt.style)
+			if !ok || *s != tt.want {
+				t.Errorf("Lookup(%s) = %+v, %v, want %+v", tt.style, s, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestStyleRegistry_Resolve(t *testing.T) {
+	r := DefaultStyleRegistry()
+	tests := []struct {
+		names []string
+		want  Style
+	}{
+		{nil, Style{}},
+		{[]string{"header", "highlight"}, Style{}},
+		{[]string{"bold", "italic", "center"}, Style{Weight: "bold", Italic: true, Align: "center"}},
+		{[]string{"right", "left"}, Style{Align: "left"}},
+		{[]string{"indent", "indent"}, Style{Indent: 2}},
+		{[]string{"border-top", "shaded"}, Style{BorderTop: "single", Shading: "D9D9D9"}},
+	}
+	for _, tt := range tests {
+		if got := r.Resolve(tt.names); got != tt.want {
+			t.Errorf("Resolve(%v) = %+v, want %+v", tt.names, got, tt.want)
+		}
+	}
+}